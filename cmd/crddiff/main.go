@@ -16,13 +16,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"runtime"
 	"syscall"
 
 	"github.com/alecthomas/kingpin/v2"
-	"github.com/oasdiff/oasdiff/diff"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 
 	"github.com/upbound/uptest/pkg/crdschema"
@@ -31,16 +35,27 @@ import (
 var (
 	app = kingpin.New("crddiff", "A tool for checking breaking API changes between two CRD OpenAPI v3 schemas. The schemas can come from either two revisions of a CRD, or from the versions declared in a single CRD.").DefaultEnvars()
 	// crddiff sub-commands
-	cmdRevision = app.Command("revision", "Compare the first schema available in a base CRD against the first schema from a revision CRD")
-	cmdSelf     = app.Command("self", "Use OpenAPI v3 schemas from a single CRD")
+	cmdRevision = app.Command("revision", "Compare the schemas available in a base CRD against the schemas from a revision CRD. "+
+		"If directories are specified, each CRD manifest in the base directory is compared against the manifest at the same relative path in the revision directory.")
+	cmdSelf = app.Command("self", "Use OpenAPI v3 schemas from a single CRD")
 )
 
 var (
 	revisionDiffOptions    = getCRDdiffCommonOptions(cmdRevision)
 	selfDiffOptions        = getCRDdiffCommonOptions(cmdSelf)
-	outputFormat           = app.Flag("output", "Output format: text, json, yaml").Default("text").Enum("text", "json", "yaml")
+	outputFormat           = app.Flag("output", "Output format: text, json, yaml, html. The html format renders a self-contained page with the base and revision schemas side by side").Default("text").Enum("text", "json", "yaml", "html")
+	textMode               = app.Flag("text-mode", "Level of detail of the text output: verbose renders a tree of all the schema changes, terse renders a line per added, deleted or type changed field, paths renders only the paths of those fields").Default(string(crdschema.TextModeVerbose)).Enum(string(crdschema.TextModeVerbose), string(crdschema.TextModeTerse), string(crdschema.TextModePaths))
+	textColor              = app.Flag("color", "Highlight the changes in the text output with colors: auto enables colors if the output is a terminal").Default("auto").Enum("auto", "always", "never")
+	explain                = app.Flag("explain", "Include the base and revision subschemas, and their descriptions, of each added, deleted or type changed field in the output").Default("false").Bool()
+	maxBreaking            = app.Flag("max-breaking", "Maximum number of breaking changes allowed. If set, this threshold instead of the presence of any breaking change decides the exit code. Negative values disable the threshold").Default(fmt.Sprint(crdschema.ThresholdDisabled)).Int()
+	maxDeletedFields       = app.Flag("max-deleted-fields", "Maximum number of deleted fields allowed. This threshold is checked in addition to the failure on any breaking change or the policy. Negative values disable the threshold").Default(fmt.Sprint(crdschema.ThresholdDisabled)).Int()
 	revisionKeepAllChanges = cmdRevision.Flag("keep-all-changes", "Include all changes (breaking and non-breaking) in the output").Default("false").Bool()
 	selfKeepAllChanges     = cmdSelf.Flag("keep-all-changes", "Include all changes (breaking and non-breaking) in the output").Default("false").Bool()
+	revisionParallelism    = cmdRevision.Flag("parallelism", "Maximum number of CRD pairs compared concurrently when directories are specified").Default(fmt.Sprint(runtime.NumCPU())).Int()
+	revisionPolicy         = cmdRevision.Flag("policy", "Policy the changes are checked against: none fails on any breaking change, "+
+		"kubernetes enforces the Kubernetes API deprecation policy depending on the maturity of each version").Default(string(crdschema.PolicyNone)).Enum(string(crdschema.PolicyNone), string(crdschema.PolicyKubernetes))
+	selfPolicy = cmdSelf.Flag("policy", "Policy the versions are checked against: none fails on any breaking change, "+
+		"kubernetes enforces the Kubernetes API deprecation policy, which allows breaking changes between the versions but requires a served, non-deprecated replacement at least as stable as each deprecated version").Default(string(crdschema.PolicyNone)).Enum(string(crdschema.PolicyNone), string(crdschema.PolicyKubernetes))
 )
 
 func getCRDdiffCommonOptions(cmd *kingpin.CmdClause) *crdschema.CommonOptions {
@@ -51,6 +66,7 @@ func getCRDdiffCommonOptions(cmd *kingpin.CmdClause) *crdschema.CommonOptions {
 }
 
 func main() {
+	cmdRevision.Validate(validateRevisionPaths)
 	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
 	case cmdRevision.FullCommand():
 		crdDiffRevision()
@@ -60,14 +76,164 @@ func main() {
 }
 
 var (
-	baseCRDPath     = cmdRevision.Arg("base", "The manifest file path of the CRD to be used as the base").Required().ExistingFile()
-	revisionCRDPath = cmdRevision.Arg("revision", "The manifest file path of the CRD to be used as a revision to the base").Required().ExistingFile()
+	baseCRDPath     = cmdRevision.Arg("base", "The manifest file path of the CRD, or a directory of CRDs, to be used as the base. A raw OpenAPI v3 schema file or a Go package pattern can be used depending on the base format").Required().String()
+	revisionCRDPath = cmdRevision.Arg("revision", "The manifest file path of the CRD, or a directory of CRDs, to be used as a revision to the base. A raw OpenAPI v3 schema file or a Go package pattern can be used depending on the revision format").Required().String()
+	baseSource      = getSourceOptions(cmdRevision, "base")
+	revisionSource  = getSourceOptions(cmdRevision, "revision")
 )
 
+func getSourceOptions(cmd *kingpin.CmdClause, input string) *crdschema.SourceOptions {
+	src := &crdschema.SourceOptions{}
+	cmd.Flag(input+"-format", "Format of the "+input+": a CRD manifest (crd), a raw OpenAPI v3 schema (openapi) or a Go package pattern for the API types from which the CRD is generated with controller-gen (go)").
+		Default(string(crdschema.InputFormatCRD)).EnumVar((*string)(&src.Format), string(crdschema.InputFormatCRD), string(crdschema.InputFormatOpenAPI), string(crdschema.InputFormatGoTypes))
+	cmd.Flag(input+"-schema-version", "The CRD version name assigned to the "+input+" if it's a raw OpenAPI v3 schema").Default(crdschema.DefaultSchemaVersion).StringVar(&src.Version)
+	cmd.Flag(input+"-group", "The API group assigned to the "+input+" if it's a raw OpenAPI v3 schema. Defaults to the group in the x-kubernetes-group-version-kind extension of the schema, if any").StringVar(&src.Group)
+	cmd.Flag(input+"-kind", "The kind assigned to the "+input+" if it's a raw OpenAPI v3 schema. Defaults to the kind in the x-kubernetes-group-version-kind extension of the schema, if any").StringVar(&src.Kind)
+	cmd.Flag(input+"-crd-name", "The name of the CRD to be selected if the "+input+" Go API types declare more than one kind, e.g., instances.ec2.aws.upbound.io").StringVar(&src.CRDName)
+	return src
+}
+
+// validateRevisionPaths checks that the base and the revision exist if
+// they are file-based inputs. The Go package patterns are not validated as
+// they are resolved by the Go tooling.
+func validateRevisionPaths(*kingpin.CmdClause) error {
+	if err := validateInputPath(*baseCRDPath, baseSource.Format); err != nil {
+		return err
+	}
+	return validateInputPath(*revisionCRDPath, revisionSource.Format)
+}
+
+// validateInputPath checks the path of an input as kingpin's
+// ExistingFileOrDir and ExistingFile do for the CRD manifests and
+// the raw OpenAPI v3 schemas, respectively.
+func validateInputPath(path string, format crdschema.InputFormat) error {
+	if format == crdschema.InputFormatGoTypes {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("path '%s' does not exist", path)
+	case err != nil:
+		return err
+	case fi.IsDir() && format == crdschema.InputFormatOpenAPI:
+		return fmt.Errorf("'%s' is a directory", path)
+	}
+	return nil
+}
+
 func crdDiffRevision() {
-	crdDiff, err := crdschema.NewRevisionDiff(*baseCRDPath, *revisionCRDPath, crdschema.WithRevisionDiffCommonOptions(revisionDiffOptions))
+	// Go API types are loaded from package patterns,
+	// which may also be directories.
+	crdInputs := baseSource.Format == crdschema.InputFormatCRD && revisionSource.Format == crdschema.InputFormatCRD
+	if crdInputs && (isDir(*baseCRDPath) || isDir(*revisionCRDPath)) {
+		crdDiffRevisionDirs()
+		return
+	}
+	crdDiff, err := crdschema.NewRevisionDiff(*baseCRDPath, *revisionCRDPath,
+		crdschema.WithRevisionDiffCommonOptions(revisionDiffOptions),
+		crdschema.WithBaseSource(*baseSource),
+		crdschema.WithRevisionSource(*revisionSource),
+		crdschema.WithPolicy(crdschema.Policy(*revisionPolicy)))
 	kingpin.FatalIfError(err, "Failed to load CRDs")
-	reportDiff(crdDiff, *revisionKeepAllChanges)
+	c, err := crdschema.GetChanges(crdDiff)
+	kingpin.FatalIfError(err, "Failed to compute CRD API changes")
+	gr := gateResult{policyEnabled: crdschema.Policy(*revisionPolicy) != crdschema.PolicyNone}
+	gr.violations, err = crdDiff.CheckPolicyChanges(c.Breaking)
+	kingpin.FatalIfError(err, "Failed to check the CRD API changes against the policy")
+	reportDiff(c, crdDiff.GroupKind(), *revisionKeepAllChanges, gr, *revisionCRDPath)
+}
+
+// gateResult is the result of checking the changes against a policy
+// and the change thresholds.
+type gateResult struct {
+	// policyEnabled is true if the changes are checked against a policy,
+	// in which case the violations instead of the breaking changes
+	// decide the exit code.
+	policyEnabled bool
+	violations    []crdschema.PolicyViolation
+	// summary contains the change counts the thresholds are checked
+	// against.
+	summary *crdschema.Summary
+	// exceeded are the descriptions of the exceeded thresholds.
+	exceeded []string
+}
+
+// failed returns true if any threshold is exceeded or the policy is
+// violated. If neither a policy nor the maximum number of breaking
+// changes is configured, failed returns true if the specified breaking
+// changes are found.
+func (gr gateResult) failed(breaking bool) bool {
+	if len(gr.exceeded) > 0 {
+		return true
+	}
+	if gr.policyEnabled {
+		return len(gr.violations) > 0
+	}
+	return breaking && *maxBreaking < 0
+}
+
+// summarize sets the summary of the specified change counts and checks
+// them against the configured thresholds.
+func (gr *gateResult) summarize(s *crdschema.Summary) {
+	gr.summary = s
+	gr.exceeded = s.CheckThresholds(changeThresholds())
+}
+
+func changeThresholds() crdschema.Thresholds {
+	return crdschema.Thresholds{
+		MaxBreaking:      *maxBreaking,
+		MaxDeletedFields: *maxDeletedFields,
+	}
+}
+
+// printSummary prints the summary of the changes, if there are any,
+// and the exceeded thresholds.
+func printSummary(l *log.Logger, gr gateResult) {
+	if gr.summary != nil && gr.summary.Total.Total() > 0 {
+		kingpin.FatalIfError(gr.summary.RenderText(l.Writer()), "Failed to write the summary")
+	}
+	printExceeded(l, gr.exceeded)
+}
+
+func printExceeded(l *log.Logger, exceeded []string) {
+	if len(exceeded) == 0 {
+		return
+	}
+	l.Println("Thresholds exceeded:")
+	for _, e := range exceeded {
+		l.Printf("- %s\n", e)
+	}
+}
+
+func printViolations(l *log.Logger, violations []crdschema.PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	l.Println("Policy violations:")
+	for _, v := range violations {
+		l.Printf("- %s\n", v)
+	}
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+func crdDiffRevisionDirs() {
+	if !isDir(*baseCRDPath) || !isDir(*revisionCRDPath) {
+		kingpin.Fatalf("Both the base and the revision must be directories to compare CRDs in directories")
+	}
+	pairs, err := crdschema.DiffPairsFromDirs(*baseCRDPath, *revisionCRDPath)
+	kingpin.FatalIfError(err, "Failed to collect the CRDs to compare")
+	results, err := crdschema.NewDiffSet(pairs,
+		crdschema.WithDiffSetParallelism(*revisionParallelism),
+		crdschema.WithDiffSetKeepAllChanges(*revisionKeepAllChanges),
+		crdschema.WithDiffSetPolicy(crdschema.Policy(*revisionPolicy)),
+		crdschema.WithDiffSetCommonOptions(revisionDiffOptions)).Run(context.Background())
+	kingpin.FatalIfError(err, "Failed to compute CRD API changes")
+	reportDiffSet(results)
 }
 
 var (
@@ -75,69 +241,77 @@ var (
 )
 
 func crdDiffSelf() {
-	crdDiff, err := crdschema.NewSelfDiff(*crdPath, crdschema.WithSelfDiffCommonOptions(selfDiffOptions))
+	crdDiff, err := crdschema.NewSelfDiff(*crdPath, crdschema.WithSelfDiffCommonOptions(selfDiffOptions), crdschema.WithSelfDiffPolicy(crdschema.Policy(*selfPolicy)))
 	kingpin.FatalIfError(err, "Failed to load CRDs")
-	reportDiff(crdDiff, *selfKeepAllChanges)
+	c, err := crdschema.GetChanges(crdDiff)
+	kingpin.FatalIfError(err, "Failed to compute CRD API changes")
+	gr := gateResult{policyEnabled: crdschema.Policy(*selfPolicy) != crdschema.PolicyNone}
+	gr.violations, err = crdDiff.CheckPolicyChanges(c.Breaking)
+	kingpin.FatalIfError(err, "Failed to check the CRD versions against the policy")
+	reportDiff(c, crdDiff.GroupKind(), *selfKeepAllChanges, gr, *crdPath)
 }
 
-func reportDiff(crdDiff crdschema.SchemaCheck, keepAllChanges bool) {
+func reportDiff(c *crdschema.Changes, gk schema.GroupKind, keepAllChanges bool, gr gateResult, name string) {
+	summary := crdschema.NewSummary()
+	summary.Add(gk, c.Counts)
+	gr.summarize(summary)
 	switch *outputFormat {
+	case "html":
+		reportHTML(c, keepAllChanges, gr, name)
 	case "json":
-		reportJSON(crdDiff, keepAllChanges)
+		reportJSON(c, keepAllChanges, gr)
 	case "yaml":
-		reportYAML(crdDiff, keepAllChanges)
+		reportYAML(c, keepAllChanges, gr)
 	default:
-		reportText(crdDiff, keepAllChanges)
+		reportText(c, keepAllChanges, gr)
 	}
 }
 
-func reportText(crdDiff crdschema.SchemaCheck, keepAllChanges bool) {
-	var versionMap map[string]*diff.Diff
-	var err error
-	if keepAllChanges {
-		versionMap, err = crdDiff.GetRawDiff()
-		kingpin.FatalIfError(err, "Failed to compute CRD API changes")
-	} else {
-		versionMap, err = crdDiff.GetBreakingChanges()
-		kingpin.FatalIfError(err, "Failed to compute CRD breaking API changes")
-	}
+// newTextRenderer returns the renderer for the text output,
+// which is written to the standard error.
+func newTextRenderer() *crdschema.TextRenderer {
+	color := *textColor == "always" || (*textColor == "auto" && term.IsTerminal(int(os.Stderr.Fd())))
+	return crdschema.NewTextRenderer(crdschema.WithTextMode(crdschema.TextMode(*textMode)), crdschema.WithTextColor(color), crdschema.WithTextExplain(*explain))
+}
+
+func reportText(c *crdschema.Changes, keepAllChanges bool, gr gateResult) {
+	versionMap := c.Diff(keepAllChanges)
 	l := log.New(os.Stderr, "", 0)
-	changeDetected := false
-	for v, d := range versionMap {
+	r := newTextRenderer()
+	for _, v := range crdschema.SortedVersionNames(versionMap) {
+		d := versionMap[v]
 		if d.Empty() {
 			continue
 		}
-		changeDetected = true
 		l.Printf("Version %q:\n", v)
-		l.Println(crdschema.GetDiffReport(d))
-	}
-
-	// Exit 1 only if breaking changes detected
-	if changeDetected {
-		// If we're showing all changes, need to check if any are breaking
-		if keepAllChanges {
-			breakingChanges, err := crdDiff.GetBreakingChanges()
-			kingpin.FatalIfError(err, "Failed to compute CRD breaking API changes")
-			for _, d := range breakingChanges {
-				if d != nil && !d.Empty() {
-					syscall.Exit(1)
-				}
-			}
-		} else {
-			// If we're only showing breaking changes, any change is breaking
-			syscall.Exit(1)
-		}
+		kingpin.FatalIfError(r.Render(os.Stderr, d), "Failed to write the text report")
 	}
-}
 
-func reportJSON(crdDiff crdschema.SchemaCheck, keepAllChanges bool) {
-	rawDiff, err := crdDiff.GetRawDiff()
-	kingpin.FatalIfError(err, "Failed to get schema changes")
+	printViolations(l, gr.violations)
+	printSummary(l, gr)
 
-	report, err := crdschema.GetChangesAsStructured(rawDiff, keepAllChanges)
+	// Exit 1 only if breaking changes detected, or if checking against
+	// a policy, only if the policy is violated
+	if gr.failed(c.HasBreakingChanges()) {
+		syscall.Exit(1)
+	}
+}
+
+// structuredReport returns the structured report of the changes.
+func structuredReport(c *crdschema.Changes, keepAllChanges bool, gr gateResult) *crdschema.ChangeReport {
+	report, err := crdschema.GetChangesAsStructured(c.Diff(keepAllChanges), true)
 	kingpin.FatalIfError(err, "Failed to get changes report")
+	report.PolicyViolations = gr.violations
+	report.Summary = gr.summary
+	printExceeded(log.New(os.Stderr, "", 0), gr.exceeded)
+	if *explain {
+		kingpin.FatalIfError(report.Explain(), "Failed to explain the changes")
+	}
+	return report
+}
 
-	data, err := json.MarshalIndent(report, "", "  ")
+func reportJSON(c *crdschema.Changes, keepAllChanges bool, gr gateResult) {
+	data, err := json.MarshalIndent(structuredReport(c, keepAllChanges, gr), "", "  ")
 	kingpin.FatalIfError(err, "Failed to marshal JSON")
 
 	if _, err := os.Stdout.Write(data); err != nil {
@@ -147,32 +321,180 @@ func reportJSON(crdDiff crdschema.SchemaCheck, keepAllChanges bool) {
 		kingpin.FatalIfError(err, "Failed to write JSON")
 	}
 
-	// Exit 1 only if breaking changes detected
-	breakingReport, err := crdschema.GetChangesAsStructured(rawDiff, false)
-	kingpin.FatalIfError(err, "Failed to check for breaking changes")
-	if !breakingReport.Empty() {
+	// Exit 1 only if breaking changes detected, or if checking against
+	// a policy, only if the policy is violated
+	if gr.failed(c.HasBreakingChanges()) {
 		syscall.Exit(1)
 	}
 }
 
-func reportYAML(crdDiff crdschema.SchemaCheck, keepAllChanges bool) {
-	rawDiff, err := crdDiff.GetRawDiff()
-	kingpin.FatalIfError(err, "Failed to get schema changes")
-
-	report, err := crdschema.GetChangesAsStructured(rawDiff, keepAllChanges)
-	kingpin.FatalIfError(err, "Failed to get changes report")
-
-	data, err := yaml.Marshal(report)
+func reportYAML(c *crdschema.Changes, keepAllChanges bool, gr gateResult) {
+	data, err := yaml.Marshal(structuredReport(c, keepAllChanges, gr))
 	kingpin.FatalIfError(err, "Failed to marshal YAML")
 
 	if _, err := os.Stdout.Write(data); err != nil {
 		kingpin.FatalIfError(err, "Failed to write YAML")
 	}
 
-	// Exit 1 only if breaking changes detected
-	breakingReport, err := crdschema.GetChangesAsStructured(rawDiff, false)
-	kingpin.FatalIfError(err, "Failed to check for breaking changes")
-	if !breakingReport.Empty() {
+	// Exit 1 only if breaking changes detected, or if checking against
+	// a policy, only if the policy is violated
+	if gr.failed(c.HasBreakingChanges()) {
+		syscall.Exit(1)
+	}
+}
+
+func reportHTML(c *crdschema.Changes, keepAllChanges bool, gr gateResult, name string) {
+	err := crdschema.RenderHTML(os.Stdout, "crddiff report", []crdschema.HTMLSection{
+		{
+			Name:             name,
+			Diffs:            c.Diff(keepAllChanges),
+			PolicyViolations: gr.violations,
+			Explain:          *explain,
+		},
+	})
+	kingpin.FatalIfError(err, "Failed to write the HTML report")
+	printExceeded(log.New(os.Stderr, "", 0), gr.exceeded)
+
+	// Exit 1 only if breaking changes detected, or if checking against
+	// a policy, only if the policy is violated
+	if gr.failed(c.HasBreakingChanges()) {
+		syscall.Exit(1)
+	}
+}
+
+func reportDiffSet(results []crdschema.DiffResult) {
+	switch *outputFormat {
+	case "html":
+		reportDiffSetHTML(results)
+	case "json", "yaml":
+		reportDiffSetStructured(results)
+	default:
+		reportDiffSetText(results)
+	}
+}
+
+func reportDiffSetText(results []crdschema.DiffResult) {
+	l := log.New(os.Stderr, "", 0)
+	tr := newTextRenderer()
+	gr := diffSetSummary(results)
+	failed := len(gr.exceeded) > 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed = true
+			l.Printf("CRD %q: %v\n", r.Pair.Name, r.Err)
+			continue
+		}
+		for _, v := range crdschema.SortedVersionNames(r.Diff) {
+			d := r.Diff[v]
+			if d.Empty() {
+				continue
+			}
+			l.Printf("CRD %q, version %q:\n", r.Pair.Name, v)
+			kingpin.FatalIfError(tr.Render(os.Stderr, d), "Failed to write the text report")
+		}
+		if len(r.PolicyViolations) > 0 {
+			l.Printf("CRD %q:\n", r.Pair.Name)
+			printViolations(l, r.PolicyViolations)
+		}
+		failed = failed || diffSetGateResult(r).failed(r.HasBreakingChanges)
+	}
+	printSummary(l, gr)
+	if failed {
+		syscall.Exit(1)
+	}
+}
+
+func diffSetGateResult(r crdschema.DiffResult) gateResult {
+	return gateResult{
+		policyEnabled: crdschema.Policy(*revisionPolicy) != crdschema.PolicyNone,
+		violations:    r.PolicyViolations,
+	}
+}
+
+// diffSetSummary returns the summary of the changes of the CRDs compared
+// without errors, checked against the configured thresholds.
+func diffSetSummary(results []crdschema.DiffResult) gateResult {
+	s := crdschema.NewSummary()
+	for _, r := range results {
+		if r.Err == nil {
+			s.Add(r.GroupKind, r.Counts)
+		}
+	}
+	gr := gateResult{}
+	gr.summarize(s)
+	return gr
+}
+
+// diffSetReport is the structured report of the changes of the CRDs
+// in the compared directories.
+type diffSetReport struct {
+	// CRDs maps the CRD manifest paths relative to the compared
+	// directories to their change reports.
+	CRDs    map[string]*crdschema.ChangeReport `json:"crds"`
+	Summary *crdschema.Summary                 `json:"summary"`
+}
+
+func reportDiffSetStructured(results []crdschema.DiffResult) {
+	reports := make(map[string]*crdschema.ChangeReport, len(results))
+	gr := diffSetSummary(results)
+	failed := len(gr.exceeded) > 0
+	l := log.New(os.Stderr, "", 0)
+	printExceeded(l, gr.exceeded)
+	for _, r := range results {
+		if r.Err != nil {
+			failed = true
+			l.Printf("CRD %q: %v\n", r.Pair.Name, r.Err)
+			continue
+		}
+		report, err := crdschema.GetChangesAsStructured(r.Diff, *revisionKeepAllChanges)
+		kingpin.FatalIfError(err, "Failed to get changes report")
+		report.PolicyViolations = r.PolicyViolations
+		if *explain {
+			kingpin.FatalIfError(report.Explain(), "Failed to explain the changes")
+		}
+		if !report.Empty() || len(report.PolicyViolations) > 0 {
+			reports[r.Pair.Name] = report
+		}
+		failed = failed || diffSetGateResult(r).failed(r.HasBreakingChanges)
+	}
+
+	dsr := diffSetReport{CRDs: reports, Summary: gr.summary}
+	var data []byte
+	var err error
+	if *outputFormat == "json" {
+		data, err = json.MarshalIndent(dsr, "", "  ")
+		kingpin.FatalIfError(err, "Failed to marshal JSON")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(dsr)
+		kingpin.FatalIfError(err, "Failed to marshal YAML")
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		kingpin.FatalIfError(err, "Failed to write the report")
+	}
+	if failed {
+		syscall.Exit(1)
+	}
+}
+
+func reportDiffSetHTML(results []crdschema.DiffResult) {
+	sections := make([]crdschema.HTMLSection, 0, len(results))
+	gr := diffSetSummary(results)
+	failed := len(gr.exceeded) > 0
+	printExceeded(log.New(os.Stderr, "", 0), gr.exceeded)
+	for _, r := range results {
+		sections = append(sections, crdschema.HTMLSection{
+			Name:             r.Pair.Name,
+			Diffs:            r.Diff,
+			PolicyViolations: r.PolicyViolations,
+			Err:              r.Err,
+			Explain:          *explain,
+		})
+		failed = failed || r.Err != nil || diffSetGateResult(r).failed(r.HasBreakingChanges)
+	}
+	err := crdschema.RenderHTML(os.Stdout, "crddiff report", sections)
+	kingpin.FatalIfError(err, "Failed to write the HTML report")
+	if failed {
 		syscall.Exit(1)
 	}
 }
@@ -16,19 +16,23 @@
 package managed
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	k8syaml "sigs.k8s.io/yaml"
 
 	log "github.com/sirupsen/logrus"
 
@@ -37,151 +41,280 @@ import (
 	"gopkg.in/yaml.v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/dynamic"
 
-	ctrl "sigs.k8s.io/controller-runtime"
+	"github.com/upbound/uptest/internal/common"
+)
 
-	"github.com/upbound/uptest/cmd/perf/internal/common"
+const (
+	// fieldManager is the field manager of the server-side applied resources.
+	fieldManager = "provider-scale"
+	// labelExperimentID is the label of the resources created by
+	// an experiment, whose value is the unique ID of the experiment.
+	labelExperimentID = "uptest.upbound.io/experiment-id"
+	// labelTemplate is the label of the resources created by an experiment,
+	// whose value is the index of the template they are created from.
+	labelTemplate = "uptest.upbound.io/experiment-template"
 )
 
-// RunExperiment runs the experiment according to command-line inputs.
-// Firstly the input manifests are deployed. After the all MRs are ready, time to readiness metrics are calculated.
-// Then, by default, all deployed MRs are deleted.
-func RunExperiment(mrTemplatePaths map[string]int, clean bool, applyInterval time.Duration) ([]common.Result, error) {
-	var timeToReadinessResults []common.Result
+var (
+	// removalPollInterval is the interval of checking whether the deleted
+	// resources have been removed.
+	removalPollInterval = 10 * time.Second
+	// removalTimeout is the maximum duration of waiting for the deleted
+	// resources to be removed.
+	removalTimeout = 60 * time.Minute
+	// readinessPollInterval is the interval of checking whether the applied
+	// resources are ready.
+	readinessPollInterval = 10 * time.Second
+	// readinessTimeout is the maximum duration of waiting for the applied
+	// resources to become ready.
+	readinessTimeout = 60 * time.Minute
+)
 
-	client := createDynamicClient()
+// template is a managed resource template of the experiment.
+type template struct {
+	// index is the index of the template in the experiment.
+	index int
+	// path is the local path or the URL of the template.
+	path string
+	// count is the number of the resources created from the template.
+	count int
+	// m is the content of the template.
+	m map[interface{}]interface{}
+	// gvr is the resource of the template's kind in the cluster.
+	gvr schema.GroupVersionResource
+}
 
-	tmpFiles, err := applyResources(mrTemplatePaths, applyInterval)
+// RunExperiment runs the experiment according to command-line inputs.
+// Firstly the input manifests are deployed. After the all MRs are ready, time to readiness metrics are calculated.
+// Then, by default, all deployed MRs are deleted, even if the experiment fails.
+// All the cluster operations use the REST config of the specified getter.
+func RunExperiment(getter genericclioptions.RESTClientGetter, mrTemplatePaths map[string]int, clean bool, applyInterval time.Duration) ([]common.Result, error) {
+	client, mapper, err := createClients(getter)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot apply resources")
-	}
-
-	if err := checkReadiness(client, mrTemplatePaths); err != nil {
-		return nil, errors.Wrap(err, "cannot check readiness of resources")
+		return nil, errors.Wrap(err, "cannot create clients")
 	}
 
-	timeToReadinessResults, err = calculateReadinessDuration(client, mrTemplatePaths)
+	templates, err := loadTemplates(mapper, mrTemplatePaths)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot calculate time to readiness")
+		return nil, errors.Wrap(err, "cannot load templates")
 	}
 
+	// only the resources labelled with the experiment's ID are tracked, so
+	// that the existing resources of the same kinds are not measured.
+	experimentID := utilrand.String(8)
+	log.Infof("Experiment ID: %s", experimentID)
+	return runExperiment(context.TODO(), client, templates, experimentID, clean, applyInterval)
+}
+
+// runExperiment applies the resources of the specified templates, labelled
+// with the specified experiment ID, and measures their time to readiness.
+// If clean is true, the applied resources are deleted afterwards, also if
+// applying them or waiting for their readiness fails.
+func runExperiment(ctx context.Context, client dynamic.Interface, templates []template, experimentID string, clean bool, applyInterval time.Duration) ([]common.Result, error) {
+	results, err := measureReadiness(ctx, client, templates, experimentID, applyInterval)
 	if clean {
 		log.Info("Deleting resources...")
-		for _, tmpFile := range tmpFiles {
-			if err := deleteResources(tmpFile); err != nil {
-				return nil, errors.Wrap(err, "cannot delete resources")
-			}
+		if derr := deleteResources(ctx, client, templates, experimentID); derr != nil {
+			return nil, utilerrors.NewAggregate([]error{err, errors.Wrap(derr, "cannot delete resources")})
 		}
 	}
-	return timeToReadinessResults, nil
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// measureReadiness applies the resources of the specified templates and
+// returns their time to readiness once they are all ready.
+func measureReadiness(ctx context.Context, client dynamic.Interface, templates []template, experimentID string, applyInterval time.Duration) ([]common.Result, error) {
+	if err := applyResources(ctx, client, templates, experimentID, applyInterval); err != nil {
+		return nil, errors.Wrap(err, "cannot apply resources")
+	}
+
+	if err := checkReadiness(ctx, client, templates, experimentID); err != nil {
+		return nil, errors.Wrap(err, "cannot check readiness of resources")
+	}
+
+	results, err := calculateReadinessDuration(ctx, client, templates, experimentID)
+	return results, errors.Wrap(err, "cannot calculate time to readiness")
 }
 
-func applyResources(mrTemplatePaths map[string]int, applyInterval time.Duration) ([]string, error) { //nolint:gocyclo // easy to follow all cases here
-	switch applyInterval { //nolint:exhaustive // not necessary to handle all duration cases
-	case 0 * time.Second:
-		f, err := os.CreateTemp("/tmp", "")
+// loadTemplates reads the specified templates and resolves the resources of
+// their kinds, so that an error is returned before any resource is applied
+// if a kind is not installed in the cluster.
+func loadTemplates(mapper meta.RESTMapper, mrTemplatePaths map[string]int) ([]template, error) {
+	paths := make([]string, 0, len(mrTemplatePaths))
+	for mrPath := range mrTemplatePaths {
+		paths = append(paths, mrPath)
+	}
+	sort.Strings(paths)
+	templates := make([]template, 0, len(paths))
+	for i, mrPath := range paths {
+		m, err := readYamlFile(mrPath)
 		if err != nil {
-			return nil, errors.Wrap(err, "cannot create input file")
+			return nil, errors.Wrapf(err, "cannot read template file %s", mrPath)
 		}
-		for mrPath, count := range mrTemplatePaths {
-			m, err := readYamlFile(mrPath)
-			if err != nil {
-				return nil, errors.Wrap(err, "cannot read template file")
-			}
-			for i := 1; i <= count; i++ {
-				if err := createManifest(f, m, i); err != nil {
-					return nil, err
-				}
-			}
-			if err := runApplyCommand(f); err != nil {
-				return nil, err
-			}
+		gvr, err := prepareGVR(mapper, m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot resolve the resource of template %s", mrPath)
 		}
+		templates = append(templates, template{index: i, path: mrPath, count: mrTemplatePaths[mrPath], m: m, gvr: gvr})
+	}
+	return templates, nil
+}
 
-		return []string{f.Name()}, nil
-	default:
-		var tmpFiles []string
-		for mrPath, count := range mrTemplatePaths {
-			m, err := readYamlFile(mrPath)
+// applyResources creates the specified numbers of resources from each
+// template with server-side apply, waiting for the apply interval between
+// the resources of a template. All the resources are applied even if some
+// of them fail, and an error is returned for each failed resource, so that
+// the applied resources can still be deleted.
+// The resources are labelled with the specified experiment ID.
+func applyResources(ctx context.Context, client dynamic.Interface, templates []template, experimentID string, applyInterval time.Duration) error {
+	var errs []error
+	for _, t := range templates {
+		for i := 1; i <= t.count; i++ {
+			u, err := createManifest(t, experimentID, i)
 			if err != nil {
-				return nil, errors.Wrap(err, "cannot read template file")
+				errs = append(errs, errors.Wrapf(err, "cannot create resource %d of template %s", i, t.path))
+				continue
 			}
-			for i := 1; i <= count; i++ {
-				f, err := os.CreateTemp("/tmp", "")
-				if err != nil {
-					return nil, errors.Wrap(err, "cannot create input file")
-				}
-				if err := createManifest(f, m, i); err != nil {
-					return nil, err
-				}
-				if err := runApplyCommand(f); err != nil {
-					return nil, err
-				}
-				if i != count {
-					time.Sleep(applyInterval)
-				}
-				tmpFiles = append(tmpFiles, f.Name())
+			log.Infof("Applying %s %s...", u.GetKind(), u.GetName())
+			if err := applyResource(ctx, client, t.gvr, u); err != nil {
+				errs = append(errs, err)
+			}
+			if applyInterval > 0 && i != t.count {
+				time.Sleep(applyInterval)
 			}
 		}
-		return tmpFiles, nil
 	}
+	return utilerrors.NewAggregate(errs)
 }
 
-func createManifest(f *os.File, m map[interface{}]interface{}, index int) error {
-	m["metadata"].(map[interface{}]interface{})["name"] = fmt.Sprintf("testperfrun%d", index)
-
-	b, err := yaml.Marshal(m)
+// createManifest returns the resource with the specified index created from
+// the template. The name of the resource is unique across the experiments
+// and their templates, and the resource is labelled with the specified
+// experiment ID and the index of the template.
+func createManifest(t template, experimentID string, index int) (*unstructured.Unstructured, error) {
+	b, err := yaml.Marshal(t.m)
 	if err != nil {
-		return errors.Wrap(err, "cannot marshal object")
+		return nil, errors.Wrap(err, "cannot marshal object")
 	}
 
-	if _, err := f.Write(b); err != nil {
-		return errors.Wrap(err, "cannot write manifest")
+	u := &unstructured.Unstructured{}
+	if err := k8syaml.Unmarshal(b, &u.Object); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal object")
 	}
-
-	if _, err := f.WriteString("\n---\n\n"); err != nil {
-		return errors.Wrap(err, "cannot write yaml separator")
+	u.SetName(fmt.Sprintf("testperfrun-%s-%d-%d", experimentID, t.index, index))
+	ls := u.GetLabels()
+	if ls == nil {
+		ls = make(map[string]string, 2)
 	}
-	return nil
+	ls[labelExperimentID] = experimentID
+	ls[labelTemplate] = strconv.Itoa(t.index)
+	u.SetLabels(ls)
+	return u, nil
+}
+
+func applyResource(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, u *unstructured.Unstructured) error {
+	_, err := client.Resource(gvr).Namespace(u.GetNamespace()).Apply(ctx, u.GetName(), u, metav1.ApplyOptions{
+		FieldManager: fieldManager,
+		Force:        true,
+	})
+	return errors.Wrapf(err, "cannot apply %s %s", u.GetKind(), objectName(u))
 }
 
-func runApplyCommand(f *os.File) error {
-	if err := f.Close(); err != nil {
-		return errors.Wrap(err, "cannot close input file")
+// deleteResources deletes the resources of the templates labelled with
+// the specified experiment ID. The resources that have already been deleted
+// are ignored. All the resources are deleted even if some of them fail, and
+// an error is returned for each failed resource. Otherwise, it waits until
+// the resources have been removed, i.e., their finalizers have deleted
+// the external resources, like kubectl delete does.
+func deleteResources(ctx context.Context, client dynamic.Interface, templates []template, experimentID string) error {
+	var errs []error
+	for _, t := range templates {
+		list, err := client.Resource(t.gvr).List(ctx, listOptions(t, experimentID))
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "cannot list %s", t.gvr.Resource))
+			continue
+		}
+		for i := range list.Items {
+			u := &list.Items[i]
+			err := client.Resource(t.gvr).Namespace(u.GetNamespace()).Delete(ctx, u.GetName(), metav1.DeleteOptions{})
+			if err != nil && !kerrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "cannot delete %s %s", u.GetKind(), objectName(u)))
+			}
+		}
 	}
-	if err := runCommand(fmt.Sprintf(`"kubectl" apply -f %s`, f.Name())); err != nil {
-		return errors.Wrap(err, "cannot execute kubectl apply command")
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
 	}
-	return nil
+	return waitForRemoval(ctx, client, templates, experimentID)
 }
 
-func deleteResources(tmpFileName string) error {
-	if err := runCommand(fmt.Sprintf(`"kubectl" delete -f %s`, tmpFileName)); err != nil {
-		return errors.Wrap(err, "cannot execute kubectl delete command")
+// waitForRemoval waits until there are no resources of the templates
+// labelled with the specified experiment ID, or the removal timeout expires.
+func waitForRemoval(ctx context.Context, client dynamic.Interface, templates []template, experimentID string) error {
+	remaining := 0
+	err := wait.PollUntilContextTimeout(ctx, removalPollInterval, removalTimeout, true, func(ctx context.Context) (bool, error) {
+		remaining = 0
+		for _, t := range templates {
+			list, err := client.Resource(t.gvr).List(ctx, listOptions(t, experimentID))
+			if err != nil {
+				return false, errors.Wrapf(err, "cannot list %s", t.gvr.Resource)
+			}
+			remaining += len(list.Items)
+		}
+		if remaining > 0 {
+			log.Infof("Waiting for %d resources to be removed...", remaining)
+		}
+		return remaining == 0, nil
+	})
+	return errors.Wrapf(err, "%d resources have not been removed", remaining)
+}
+
+// listOptions returns the options listing the resources created from
+// the specified template by the experiment with the specified ID.
+func listOptions(t template, experimentID string) metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			labelExperimentID: experimentID,
+			labelTemplate:     strconv.Itoa(t.index),
+		}).String(),
 	}
-	return nil
 }
 
-func checkReadiness(client dynamic.Interface, mrTemplatePaths map[string]int) error {
-	for mrPath := range mrTemplatePaths {
-		m, err := readYamlFile(mrPath)
-		if err != nil {
-			return errors.Wrap(err, "cannot read template file")
-		}
+// objectName returns the name of the specified object, prefixed with its
+// namespace if it is namespaced.
+func objectName(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return u.GetName()
+	}
+	return u.GetNamespace() + "/" + u.GetName()
+}
 
-		for {
+// checkReadiness waits until the resources of the templates labelled with
+// the specified experiment ID are ready, or the readiness timeout expires.
+func checkReadiness(ctx context.Context, client dynamic.Interface, templates []template, experimentID string) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	for _, t := range templates {
+		err := wait.PollUntilContextCancel(ctx, readinessPollInterval, true, func(ctx context.Context) (bool, error) {
 			log.Info("Checking readiness of resources...")
-			list, err := client.Resource(prepareGVR(m)).List(context.TODO(), metav1.ListOptions{})
+			list, err := client.Resource(t.gvr).List(ctx, listOptions(t, experimentID))
 			if err != nil {
-				return errors.Wrap(err, "cannot list resources")
-			}
-			if isReady(list) {
-				break
+				return false, errors.Wrap(err, "cannot list resources")
 			}
-			time.Sleep(10 * time.Second)
+			return isReady(list), nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "resources of template %s are not ready", t.path)
 		}
 	}
 	return nil
@@ -189,63 +322,55 @@ func checkReadiness(client dynamic.Interface, mrTemplatePaths map[string]int) er
 
 func isReady(list *unstructured.UnstructuredList) bool {
 	for _, l := range list.Items {
-		if l.Object["status"] == nil {
+		if c := readyCondition(l); c == nil || c["status"] != "True" {
 			return false
 		}
-		conditions := l.Object["status"].(map[string]interface{})["conditions"].([]interface{})
-
-		status := ""
-		for _, condition := range conditions {
-			c := condition.(map[string]interface{})
-			if c["type"] == "Ready" {
-				status = c["status"].(string)
-			}
-		}
+	}
+	return true
+}
 
-		if status == "False" || status == "" {
-			return false
+// readyCondition returns the Ready condition of the specified resource,
+// or nil if the resource does not have a well-formed Ready condition.
+func readyCondition(u unstructured.Unstructured) map[string]interface{} {
+	conditions, found, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if err != nil || !found {
+		return nil
+	}
+	for _, condition := range conditions {
+		if c, ok := condition.(map[string]interface{}); ok && c["type"] == "Ready" {
+			return c
 		}
 	}
-	return true
+	return nil
 }
 
-func calculateReadinessDuration(client dynamic.Interface, mrTemplatePaths map[string]int) ([]common.Result, error) {
+func calculateReadinessDuration(ctx context.Context, client dynamic.Interface, templates []template, experimentID string) ([]common.Result, error) {
 	var results []common.Result //nolint:prealloc // The size of the slice is not previously known.
-	for mrPath := range mrTemplatePaths {
+	for _, t := range templates {
 		log.Info("Calculating readiness time of resources...")
 		var result common.Result
 
-		m, err := readYamlFile(mrPath)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot read template file")
-		}
-
-		list, err := client.Resource(prepareGVR(m)).List(context.TODO(), metav1.ListOptions{})
+		list, err := client.Resource(t.gvr).List(ctx, listOptions(t, experimentID))
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot list resources")
 		}
 		for _, l := range list.Items {
-			readinessTime := metav1.Time{}
-			creationTimestamp := l.GetCreationTimestamp()
-			conditions := l.Object["status"].(map[string]interface{})["conditions"].([]interface{})
-
-			for _, condition := range conditions {
-				c := condition.(map[string]interface{})
-
-				if c["type"] == "Ready" && c["status"] == "True" {
-					t, err := time.Parse(time.RFC3339, c["lastTransitionTime"].(string))
-					if err != nil {
-						return nil, err
-					}
-					readinessTime.Time = t
-
-					diff := readinessTime.Sub(creationTimestamp.Time)
-					result.Data = append(result.Data, common.Data{Value: diff.Seconds()})
-					break
-				}
+			c := readyCondition(l)
+			if c == nil || c["status"] != "True" {
+				continue
+			}
+			ltt, _, err := unstructured.NestedString(c, "lastTransitionTime")
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot get the readiness time of %s", objectName(&l))
+			}
+			readinessTime, err := time.Parse(time.RFC3339, ltt)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot parse the readiness time of %s", objectName(&l))
 			}
+			diff := readinessTime.Sub(l.GetCreationTimestamp().Time)
+			result.Data = append(result.Data, common.Data{Value: diff.Seconds()})
 		}
-		result.Metric = fmt.Sprintf("Time to Readiness of %s", m["kind"])
+		result.Metric = fmt.Sprintf("Time to Readiness of %s", t.m["kind"])
 		result.MetricUnit = "seconds"
 		result.Average, result.Peak = common.CalculateAverageAndPeak(result.Data)
 		results = append(results, result)
@@ -253,17 +378,23 @@ func calculateReadinessDuration(client dynamic.Interface, mrTemplatePaths map[st
 	return results, nil
 }
 
-func prepareGVR(m map[interface{}]interface{}) schema.GroupVersionResource {
-	apiVersion := strings.Split(m["apiVersion"].(string), "/")
-	kind := strings.ToLower(m["kind"].(string))
-	pluralGVR, _ := meta.UnsafeGuessKindToResource(
-		schema.GroupVersionKind{
-			Group:   apiVersion[0],
-			Version: apiVersion[1],
-			Kind:    kind,
-		},
-	)
-	return pluralGVR
+// prepareGVR returns the resource of the template's kind using the specified
+// discovery-backed REST mapper, instead of guessing the plural name of the
+// kind, e.g., policies for Policy.
+func prepareGVR(mapper meta.RESTMapper, m map[interface{}]interface{}) (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(fmt.Sprint(m["apiVersion"]))
+	if err != nil {
+		return schema.GroupVersionResource{}, errors.Wrap(err, "cannot parse apiVersion")
+	}
+	gvk := gv.WithKind(fmt.Sprint(m["kind"]))
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return schema.GroupVersionResource{}, errors.Errorf("kind %s of API version %s is not installed in the cluster", gvk.Kind, gv)
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, errors.Wrapf(err, "cannot get the resource of %s", gvk)
+	}
+	return mapping.Resource, nil
 }
 
 func readYamlFile(pathOrURL string) (map[interface{}]interface{}, error) {
@@ -308,23 +439,21 @@ func readYamlFile(pathOrURL string) (map[interface{}]interface{}, error) {
 	return m, nil
 }
 
-func createDynamicClient() dynamic.Interface {
-	return dynamic.NewForConfigOrDie(ctrl.GetConfigOrDie())
-}
-
-func runCommand(command string) error {
-	cmd := exec.Command("bash", "-c", command) //nolint:noctx // #nosec G204
-	stdout, _ := cmd.StdoutPipe()
-	if err := cmd.Start(); err != nil {
-		return errors.Wrap(err, "cannot start kubectl")
+// createClients creates the dynamic client and the discovery-backed REST
+// mapper from the REST config of the specified getter, so that they target
+// the same cluster.
+func createClients(getter genericclioptions.RESTClientGetter) (dynamic.Interface, meta.RESTMapper, error) {
+	c, err := getter.ToRESTConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot get REST config")
 	}
-	sc := bufio.NewScanner(stdout)
-	sc.Split(bufio.ScanLines)
-	for sc.Scan() {
-		log.Info(sc.Text())
+	client, err := dynamic.NewForConfig(c)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot create dynamic client")
 	}
-	if err := cmd.Wait(); err != nil {
-		return errors.Wrap(err, "cannot wait for the command exit")
+	mapper, err := getter.ToRESTMapper()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot create REST mapper")
 	}
-	return nil
+	return client, mapper, nil
 }
@@ -0,0 +1,474 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managed
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/upbound/uptest/internal/common"
+)
+
+var gvrBucket = schema.GroupVersionResource{Group: "s3.aws.upbound.io", Version: "v1beta1", Resource: "buckets"}
+
+func newFakeClient(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvrBucket: "BucketList"}, objs...)
+}
+
+func newMapper() *meta.DefaultRESTMapper {
+	m := meta.NewDefaultRESTMapper(nil)
+	m.Add(gvrBucket.GroupVersion().WithKind("Bucket"), meta.RESTScopeRoot)
+	return m
+}
+
+// newBucket returns a bucket labelled with the specified experiment ID,
+// if any, and the first template.
+func newBucket(name, experimentID string) *unstructured.Unstructured {
+	return newTemplateBucket(name, experimentID, 0)
+}
+
+// newTemplateBucket returns a bucket labelled with the specified experiment
+// ID, if any, and the specified template.
+func newTemplateBucket(name, experimentID string, templateIndex int) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("s3.aws.upbound.io/v1beta1")
+	u.SetKind("Bucket")
+	u.SetName(name)
+	if experimentID != "" {
+		u.SetLabels(map[string]string{labelExperimentID: experimentID, labelTemplate: strconv.Itoa(templateIndex)})
+	}
+	return u
+}
+
+// failOn returns whether the specified name is one of the failed names.
+func failOn(name string, failed []string) bool {
+	for _, n := range failed {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadTemplates(t *testing.T) {
+	gvrPolicy := schema.GroupVersionResource{Group: "iam.aws.upbound.io", Version: "v1beta1", Resource: "policies"}
+	mapper := newMapper()
+	mapper.AddSpecific(gvrPolicy.GroupVersion().WithKind("Policy"), gvrPolicy, gvrPolicy.GroupVersion().WithResource("policy"), meta.RESTScopeRoot)
+
+	type want struct {
+		gvrs []schema.GroupVersionResource
+		err  string
+	}
+	cases := map[string]struct {
+		reason string
+		paths  map[string]int
+		want   want
+	}{
+		"Resolved": {
+			reason: "The resources of the templates' kinds should be resolved with the REST mapper.",
+			paths:  map[string]int{"testdata/policy.yaml": 1},
+			want: want{
+				gvrs: []schema.GroupVersionResource{gvrPolicy},
+			},
+		},
+		"NotInstalled": {
+			reason: "An error should be returned if a template's kind is not installed in the cluster.",
+			paths:  map[string]int{"testdata/policy.yaml": 1, "testdata/missing-kind.yaml": 1},
+			want: want{
+				err: "cannot resolve the resource of template testdata/missing-kind.yaml: kind Table of API version dynamodb.aws.upbound.io/v1beta1 is not installed in the cluster",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			templates, err := loadTemplates(mapper, tc.paths)
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.want.err, gotErr); diff != "" {
+				t.Errorf("\n%s\nloadTemplates(...): error: -want, +got:\n%s", tc.reason, diff)
+			}
+			var gvrs []schema.GroupVersionResource
+			for _, tmpl := range templates {
+				gvrs = append(gvrs, tmpl.gvr)
+			}
+			if diff := cmp.Diff(tc.want.gvrs, gvrs); diff != "" {
+				t.Errorf("\n%s\nloadTemplates(...): resources: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestApplyResources(t *testing.T) {
+	type want struct {
+		applied []string
+		errs    []string
+	}
+	cases := map[string]struct {
+		reason string
+		paths  map[string]int
+		failed []string
+		want   want
+	}{
+		"Success": {
+			reason: "All the resources should be server-side applied with the template's content and the experiment's ID.",
+			paths:  map[string]int{"testdata/bucket.yaml": 2},
+			want: want{
+				applied: []string{"testperfrun-abc-0-1", "testperfrun-abc-0-2"},
+			},
+		},
+		"PerObjectErrors": {
+			reason: "The remaining resources should be applied if some fail, and an error should be reported for each failed resource.",
+			paths:  map[string]int{"testdata/bucket.yaml": 3},
+			failed: []string{"testperfrun-abc-0-1", "testperfrun-abc-0-3"},
+			want: want{
+				applied: []string{"testperfrun-abc-0-1", "testperfrun-abc-0-2", "testperfrun-abc-0-3"},
+				errs:    []string{"cannot apply Bucket testperfrun-abc-0-1: patch failed", "cannot apply Bucket testperfrun-abc-0-3: patch failed"},
+			},
+		},
+		"TemplatesOfSameKind": {
+			reason: "The resources of the templates of the same kind should have distinct names.",
+			paths:  map[string]int{"testdata/bucket.yaml": 1, "testdata/bucket-copy.yaml": 2},
+			want: want{
+				applied: []string{"testperfrun-abc-0-1", "testperfrun-abc-0-2", "testperfrun-abc-1-1"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := newFakeClient()
+			var applied []string
+			// the object tracker of the fake client cannot create resources
+			// with server-side apply, so the applies are only recorded.
+			client.PrependReactor("patch", "buckets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				a := action.(k8stesting.PatchAction)
+				if a.GetPatchType() != types.ApplyPatchType {
+					t.Errorf("\n%s\napplyResources(...): unexpected patch type: %s", tc.reason, a.GetPatchType())
+				}
+				for _, s := range []string{`"region":"us-west-1"`, `"` + labelExperimentID + `":"abc"`} {
+					if !strings.Contains(string(a.GetPatch()), s) {
+						t.Errorf("\n%s\napplyResources(...): %s is missing from the patch: %s", tc.reason, s, a.GetPatch())
+					}
+				}
+				applied = append(applied, a.GetName())
+				if failOn(a.GetName(), tc.failed) {
+					return true, nil, errors.New("patch failed")
+				}
+				return true, newBucket(a.GetName(), "abc"), nil
+			})
+
+			templates, err := loadTemplates(newMapper(), tc.paths)
+			if err != nil {
+				t.Fatalf("loadTemplates(...): unexpected error: %v", err)
+			}
+			err = applyResources(context.Background(), client, templates, "abc", 0)
+			if diff := cmp.Diff(tc.want.applied, applied); diff != "" {
+				t.Errorf("\n%s\napplyResources(...): applied resources: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.errs, errorStrings(err)); diff != "" {
+				t.Errorf("\n%s\napplyResources(...): errors: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteResources(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		failed    []string
+		remaining []string
+		errs      []string
+	}{
+		"Success": {
+			reason:    "Only the resources labelled with the experiment's ID should be deleted.",
+			remaining: []string{"existing", "testperfrun-def-0-1"},
+		},
+		"PerObjectErrors": {
+			reason:    "The remaining resources should be deleted if some fail, and an error should be reported for each failed resource.",
+			failed:    []string{"testperfrun-abc-0-1"},
+			remaining: []string{"existing", "testperfrun-abc-0-1", "testperfrun-def-0-1"},
+			errs:      []string{"cannot delete Bucket testperfrun-abc-0-1: delete failed"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := newFakeClient(newBucket("existing", ""), newBucket("testperfrun-abc-0-1", "abc"),
+				newBucket("testperfrun-abc-0-2", "abc"), newBucket("testperfrun-def-0-1", "def"))
+			client.PrependReactor("delete", "buckets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if failOn(action.(k8stesting.DeleteAction).GetName(), tc.failed) {
+					return true, nil, errors.New("delete failed")
+				}
+				return false, nil, nil
+			})
+
+			err := deleteResources(context.Background(), client, []template{{gvr: gvrBucket}}, "abc")
+			if diff := cmp.Diff(tc.errs, errorStrings(err)); diff != "" {
+				t.Errorf("\n%s\ndeleteResources(...): errors: -want, +got:\n%s", tc.reason, diff)
+			}
+			l, err := client.Resource(gvrBucket).List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("List(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.remaining, names(l.Items)); diff != "" {
+				t.Errorf("\n%s\ndeleteResources(...): remaining resources: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteResourcesWaitsForRemoval(t *testing.T) {
+	interval, timeout := removalPollInterval, removalTimeout
+	removalPollInterval, removalTimeout = time.Millisecond, 100*time.Millisecond
+	t.Cleanup(func() {
+		removalPollInterval, removalTimeout = interval, timeout
+	})
+
+	cases := map[string]struct {
+		reason string
+		// removeAfter is the number of the lists after which the deleted
+		// resource is removed, or zero if it is never removed.
+		removeAfter int
+		wantLists   int
+		wantErr     string
+	}{
+		"Removed": {
+			reason:      "Deletion should wait until the resources blocked by finalizers have been removed.",
+			removeAfter: 3,
+			wantLists:   4,
+		},
+		"Timeout": {
+			reason:  "An error should be returned if the resources have not been removed before the timeout.",
+			wantErr: "1 resources have not been removed: context deadline exceeded",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := newFakeClient(newBucket("testperfrun-abc-0-1", "abc"))
+			// the deleted resource is kept as if its finalizer has not
+			// deleted the external resource yet.
+			client.PrependReactor("delete", "buckets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+			lists := 0
+			client.PrependReactor("list", "buckets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				lists++
+				if tc.removeAfter > 0 && lists > tc.removeAfter {
+					if err := client.Tracker().Delete(gvrBucket, "", "testperfrun-abc-0-1"); err != nil && !kerrors.IsNotFound(err) {
+						t.Fatalf("Delete(...): unexpected error: %v", err)
+					}
+				}
+				return false, nil, nil
+			})
+
+			err := deleteResources(context.Background(), client, []template{{gvr: gvrBucket}}, "abc")
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.wantErr, gotErr); diff != "" {
+				t.Errorf("\n%s\ndeleteResources(...): error: -want, +got:\n%s", tc.reason, diff)
+			}
+			// the first list is of the resources to delete
+			if tc.wantLists > 0 && lists != tc.wantLists {
+				t.Errorf("\n%s\ndeleteResources(...): expected %d lists, got %d", tc.reason, tc.wantLists, lists)
+			}
+		})
+	}
+}
+
+func TestCalculateReadinessDuration(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ready := func(u *unstructured.Unstructured, after time.Duration) *unstructured.Unstructured {
+		u.SetCreationTimestamp(metav1.NewTime(created))
+		u.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":               "Ready",
+					"status":             "True",
+					"lastTransitionTime": created.Add(after).Format(time.RFC3339),
+				},
+			},
+		}
+		return u
+	}
+	client := newFakeClient(ready(newBucket("existing", ""), time.Hour),
+		ready(newBucket("testperfrun-abc-0-1", "abc"), 10*time.Second),
+		ready(newBucket("testperfrun-abc-0-2", "abc"), 20*time.Second),
+		ready(newBucket("testperfrun-def-0-1", "def"), time.Minute),
+		ready(newTemplateBucket("testperfrun-abc-1-1", "abc", 1), 40*time.Second),
+		withConditions(newBucket("testperfrun-abc-0-3", "abc"), "Ready"))
+
+	templates := []template{
+		{index: 0, m: map[interface{}]interface{}{"kind": "Bucket"}, gvr: gvrBucket},
+		{index: 1, m: map[interface{}]interface{}{"kind": "Bucket"}, gvr: gvrBucket},
+	}
+	got, err := calculateReadinessDuration(context.Background(), client, templates, "abc")
+	if err != nil {
+		t.Fatalf("calculateReadinessDuration(...): unexpected error: %v", err)
+	}
+	want := []common.Result{{
+		Metric:     "Time to Readiness of Bucket",
+		MetricUnit: "seconds",
+		Data:       []common.Data{{Value: 10}, {Value: 20}},
+		Average:    15,
+		Peak:       20,
+	}, {
+		Metric:     "Time to Readiness of Bucket",
+		MetricUnit: "seconds",
+		Data:       []common.Data{{Value: 40}},
+		Average:    40,
+		Peak:       40,
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("calculateReadinessDuration(...): only the ready resources labelled with the experiment's ID and each template should be measured: -want, +got:\n%s", diff)
+	}
+}
+
+// withConditions sets the specified conditions in the status of the
+// specified resource.
+func withConditions(u *unstructured.Unstructured, conditions interface{}) *unstructured.Unstructured {
+	u.Object["status"] = map[string]interface{}{"conditions": conditions}
+	return u
+}
+
+func TestCheckReadiness(t *testing.T) {
+	interval, timeout := readinessPollInterval, readinessTimeout
+	readinessPollInterval, readinessTimeout = time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() {
+		readinessPollInterval, readinessTimeout = interval, timeout
+	})
+
+	readyCondition := map[string]interface{}{"type": "Ready", "status": "True"}
+	cases := map[string]struct {
+		reason  string
+		bucket  *unstructured.Unstructured
+		wantErr bool
+	}{
+		"Ready": {
+			reason: "No error should be returned if the resources are ready.",
+			bucket: withConditions(newBucket("testperfrun-abc-0-1", "abc"), []interface{}{readyCondition}),
+		},
+		"NotReady": {
+			reason:  "An error should be returned if the resources do not become ready before the timeout.",
+			bucket:  withConditions(newBucket("testperfrun-abc-0-1", "abc"), []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}}),
+			wantErr: true,
+		},
+		"NoStatus": {
+			reason:  "A resource without a status should not be ready.",
+			bucket:  newBucket("testperfrun-abc-0-1", "abc"),
+			wantErr: true,
+		},
+		"MalformedConditions": {
+			reason:  "A resource with malformed conditions should not be ready.",
+			bucket:  withConditions(newBucket("testperfrun-abc-0-1", "abc"), []interface{}{"Ready", readyCondition["status"]}),
+			wantErr: true,
+		},
+		"ConditionsNotAList": {
+			reason:  "A resource whose conditions are not a list should not be ready.",
+			bucket:  withConditions(newBucket("testperfrun-abc-0-1", "abc"), readyCondition),
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := newFakeClient(tc.bucket)
+			err := checkReadiness(context.Background(), client, []template{{gvr: gvrBucket}}, "abc")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\ncheckReadiness(...): unexpected error: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestRunExperimentCleansUp(t *testing.T) {
+	interval, timeout := removalPollInterval, removalTimeout
+	removalPollInterval, removalTimeout = time.Millisecond, 100*time.Millisecond
+	t.Cleanup(func() {
+		removalPollInterval, removalTimeout = interval, timeout
+	})
+
+	client := newFakeClient()
+	// the applied resources are added to the object tracker, which cannot
+	// create resources with server-side apply.
+	client.PrependReactor("patch", "buckets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.PatchAction).GetName()
+		if name == "testperfrun-abc-0-2" {
+			return true, nil, errors.New("patch failed")
+		}
+		u := newBucket(name, "abc")
+		if err := client.Tracker().Add(u); err != nil {
+			t.Fatalf("Add(...): unexpected error: %v", err)
+		}
+		return true, u, nil
+	})
+
+	templates, err := loadTemplates(newMapper(), map[string]int{"testdata/bucket.yaml": 3})
+	if err != nil {
+		t.Fatalf("loadTemplates(...): unexpected error: %v", err)
+	}
+	_, err = runExperiment(context.Background(), client, templates, "abc", true, 0)
+	if diff := cmp.Diff([]string{"cannot apply Bucket testperfrun-abc-0-2: patch failed"}, errorStrings(err)); diff != "" {
+		t.Errorf("runExperiment(...): the apply errors should be returned: -want, +got:\n%s", diff)
+	}
+	l, err := client.Resource(gvrBucket).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{}, names(l.Items)); diff != "" {
+		t.Errorf("runExperiment(...): the applied resources should be deleted if applying fails: -want, +got:\n%s", diff)
+	}
+}
+
+func names(objs []unstructured.Unstructured) []string {
+	ns := make([]string, 0, len(objs))
+	for _, u := range objs {
+		ns = append(ns, u.GetName())
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+func errorStrings(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var agg interface{ Errors() []error }
+	if !errors.As(err, &agg) {
+		return []string{err.Error()}
+	}
+	ss := make([]string, 0, len(agg.Errors()))
+	for _, e := range agg.Errors() {
+		ss = append(ss, e.Error())
+	}
+	return ss
+}
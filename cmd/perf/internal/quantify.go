@@ -23,14 +23,16 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/upbound/uptest/cmd/perf/internal/common"
 	"github.com/upbound/uptest/cmd/perf/internal/managed"
+	"github.com/upbound/uptest/internal/common"
 
 	"github.com/prometheus/common/model"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 // QuantifyOptions represents the options of quantify command
@@ -47,11 +49,14 @@ type QuantifyOptions struct {
 	nodeIP            string
 	applyInterval     time.Duration
 	timeout           time.Duration
+	configFlags       *genericclioptions.ConfigFlags
 }
 
 // NewCmdQuantify creates a cobra command
 func NewCmdQuantify() *cobra.Command {
-	o := QuantifyOptions{}
+	o := QuantifyOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+	}
 	o.cmd = &cobra.Command{
 		Use: "provider-scale [flags]",
 		Short: "This tool collects CPU & Memory Utilization and time to readiness of MRs metrics of providers and " +
@@ -73,6 +78,13 @@ func NewCmdQuantify() *cobra.Command {
 	o.cmd.Flags().StringVar(&o.nodeIP, "node", "", "Node IP")
 	o.cmd.Flags().DurationVar(&o.applyInterval, "apply-interval", 0*time.Second, "Elapsed time between applying two manifests to the cluster. Example = 10s. This means that examples will be applied every 10 seconds.")
 	o.cmd.Flags().DurationVar(&o.timeout, "timeout", 120*time.Minute, "Timeout for the experiment")
+	// only the kubeconfig and the context are configurable, and they are
+	// used for all the cluster operations.
+	kf := pflag.NewFlagSet("kubeconfig", pflag.ContinueOnError)
+	o.configFlags.AddFlags(kf)
+	for _, name := range []string{"kubeconfig", "context"} {
+		o.cmd.Flags().AddFlag(kf.Lookup(name))
+	}
 
 	if err := o.cmd.MarkFlagRequired("provider-pods"); err != nil {
 		panic(err)
@@ -92,7 +104,7 @@ func (o *QuantifyOptions) Run(_ *cobra.Command, _ []string) error {
 	results := make(chan []common.Result, 5)
 	errChan := make(chan error, 1)
 	go func() {
-		timeToReadinessResults, err := managed.RunExperiment(o.mrPaths, o.clean, o.applyInterval)
+		timeToReadinessResults, err := managed.RunExperiment(o.configFlags, o.mrPaths, o.clean, o.applyInterval)
 		if err != nil {
 			errChan <- errors.Wrap(err, "cannot run experiment")
 			return
@@ -0,0 +1,267 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/uptest/internal/common"
+)
+
+// aggregateMetric is a duration aggregated by an aggregator.
+type aggregateMetric struct {
+	// event is the event of the measurements with the duration.
+	event event
+	// pendingTitle is the title of the number of the pending resources,
+	// which have not reached the event yet.
+	pendingTitle string
+	// data returns the duration in seconds of the specified measurement
+	// with the time of the event.
+	data func(m measurement) common.Data
+}
+
+var (
+	// metricTTR is the time-to-readiness.
+	metricTTR = aggregateMetric{
+		event:        eventReady,
+		pendingTitle: "NOT READY",
+		data: func(m measurement) common.Data {
+			return common.Data{Timestamp: *m.ReadyTime, Value: *m.TTRSeconds}
+		},
+	}
+	// metricTimeToDeletion is the duration from the deletion until
+	// the removal of a resource.
+	metricTimeToDeletion = aggregateMetric{
+		event:        eventDeleted,
+		pendingTitle: "NOT DELETED",
+		data: func(m measurement) common.Data {
+			return common.Data{Timestamp: *m.DeletionTime, Value: m.Phases[phaseDeletionToFinalizerRemoval]}
+		},
+	}
+)
+
+// apiStatistics are the duration statistics of an API or an API group.
+type apiStatistics struct {
+	// API is either a group/version/kind or a group.
+	API string `json:"api"`
+	// Pending is the number of the resources that have not reached
+	// the measured event, e.g., that are not ready.
+	Pending int `json:"pending"`
+	// Reasons maps the condition and event reasons of the not-ready
+	// resources, e.g., Synced: ReconcileError, to the numbers of the
+	// resources with those reasons. Only available with --include-unready.
+	Reasons map[string]int `json:"reasons,omitempty"`
+	common.Statistics
+}
+
+// aggregateReport is the structured output of the aggregated statistics.
+type aggregateReport struct {
+	GVKs   []apiStatistics `json:"gvks"`
+	Groups []apiStatistics `json:"groups"`
+}
+
+// aggregator is a reporter aggregating a metric of the measurements per
+// GVK and per API group instead of reporting the individual measurements.
+type aggregator struct {
+	w      io.Writer
+	format string
+	metric aggregateMetric
+	mu     sync.Mutex
+	// data are the durations in seconds per GVK.
+	data map[schema.GroupVersionKind][]common.Data
+	// pending are the numbers of the pending resources per GVK.
+	pending map[schema.GroupVersionKind]int
+	// reasons are the numbers of the not-ready resources per GVK and
+	// reason.
+	reasons map[schema.GroupVersionKind]map[string]int
+}
+
+func newAggregator(format string, w io.Writer, metric aggregateMetric) (*aggregator, error) {
+	switch format {
+	case outputText, outputJSON, outputYAML:
+	default:
+		return nil, errors.Errorf("output format %q is not supported with aggregated statistics: must be one of text, json, yaml", format)
+	}
+	return &aggregator{
+		w:       w,
+		format:  format,
+		metric:  metric,
+		data:    make(map[schema.GroupVersionKind][]common.Data),
+		pending: make(map[schema.GroupVersionKind]int),
+		reasons: make(map[schema.GroupVersionKind]map[string]int),
+	}, nil
+}
+
+func (a *aggregator) add(m measurement) error {
+	if m.Event != a.metric.event && m.Event != eventUnready {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	gvk := schema.GroupVersionKind{Group: m.Group, Version: m.Version, Kind: m.Kind}
+	if m.Event == eventUnready {
+		a.pending[gvk]++
+		for _, r := range m.Unready.reasons() {
+			if a.reasons[gvk] == nil {
+				a.reasons[gvk] = make(map[string]int)
+			}
+			a.reasons[gvk][r]++
+		}
+		return nil
+	}
+	a.data[gvk] = append(a.data[gvk], a.metric.data(m))
+	return nil
+}
+
+// addPending counts a pending resource of the specified GVK.
+func (a *aggregator) addPending(gvk schema.GroupVersionKind) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending[gvk]++
+}
+
+// report returns the statistics per GVK and per group, sorted by
+// the API names.
+func (a *aggregator) report() aggregateReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	gvks := make(map[string]schema.GroupVersionKind)
+	for gvk := range a.data {
+		gvks[gvkString(gvk)] = gvk
+	}
+	for gvk := range a.pending {
+		gvks[gvkString(gvk)] = gvk
+	}
+	groupData := make(map[string][]common.Data)
+	groupPending := make(map[string]int)
+	groupReasons := make(map[string]map[string]int)
+	r := aggregateReport{
+		GVKs:   make([]apiStatistics, 0, len(gvks)),
+		Groups: []apiStatistics{},
+	}
+	for name, gvk := range gvks {
+		r.GVKs = append(r.GVKs, apiStatistics{
+			API:        name,
+			Pending:    a.pending[gvk],
+			Reasons:    a.reasons[gvk],
+			Statistics: common.CalculateStatistics(a.data[gvk]),
+		})
+		for reason, n := range a.reasons[gvk] {
+			if groupReasons[gvk.Group] == nil {
+				groupReasons[gvk.Group] = make(map[string]int)
+			}
+			groupReasons[gvk.Group][reason] += n
+		}
+		groupData[gvk.Group] = append(groupData[gvk.Group], a.data[gvk]...)
+		groupPending[gvk.Group] += a.pending[gvk]
+	}
+	for g := range groupPending {
+		r.Groups = append(r.Groups, apiStatistics{
+			API:        g,
+			Pending:    groupPending[g],
+			Reasons:    groupReasons[g],
+			Statistics: common.CalculateStatistics(groupData[g]),
+		})
+	}
+	sort.Slice(r.GVKs, func(i, j int) bool {
+		return r.GVKs[i].API < r.GVKs[j].API
+	})
+	sort.Slice(r.Groups, func(i, j int) bool {
+		return r.Groups[i].API < r.Groups[j].API
+	})
+	return r
+}
+
+func (a *aggregator) flush() error {
+	r := a.report()
+	var buff []byte
+	var err error
+	switch a.format {
+	case outputJSON:
+		buff, err = json.MarshalIndent(r, "", "  ")
+		buff = append(buff, '\n')
+	case outputYAML:
+		buff, err = yaml.Marshal(r)
+	default:
+		return errors.Wrap(r.renderText(a.w, a.metric.pendingTitle), "failed to write the aggregated statistics")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the aggregated statistics")
+	}
+	_, err = a.w.Write(buff)
+	return errors.Wrap(err, "failed to write the aggregated statistics")
+}
+
+// renderText writes the statistics as tables of the GVKs and the groups,
+// with the specified title for the numbers of the pending resources.
+func (r aggregateReport) renderText(w io.Writer, pendingTitle string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if err := renderTable(tw, "GVK", pendingTitle, r.GVKs); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(tw); err != nil {
+		return err
+	}
+	if err := renderTable(tw, "GROUP", pendingTitle, r.Groups); err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !r.hasReasons() {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	return renderReasons(w, r.GVKs)
+}
+
+// hasReasons returns true if the reasons of the not-ready resources are
+// available for any of the GVKs.
+func (r aggregateReport) hasReasons() bool {
+	for _, s := range r.GVKs {
+		if len(s.Reasons) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func renderTable(w io.Writer, title, pendingTitle string, stats []apiStatistics) error {
+	if _, err := fmt.Fprintf(w, "%s\tCOUNT\t%s\tMIN\tMAX\tMEAN\tP50\tP90\tP99\n", title, pendingTitle); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%.0f\t%.0f\t%.1f\t%.0f\t%.0f\t%.0f\n", s.API, s.Count, s.Pending,
+			s.Min, s.Max, s.Mean, s.P50, s.P90, s.P99); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gvkString(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("%s/%s/%s", gvk.Group, gvk.Version, gvk.Kind)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/upbound/uptest/internal/common"
+)
+
+func withKind(m measurement, kind string) measurement {
+	m.Kind = kind
+	return m
+}
+
+func unreadyMeasurement(kind string, d *unreadyDetails) measurement {
+	return measurement{
+		Event:   eventUnready,
+		Group:   "ec2.aws.upbound.io",
+		Version: "v1beta1",
+		Kind:    kind,
+		Unready: d,
+	}
+}
+
+func TestAggregatorReport(t *testing.T) {
+	subnet := schema.GroupVersionKind{Group: "ec2.aws.upbound.io", Version: "v1beta1", Kind: "Subnet"}
+	cases := map[string]struct {
+		reason       string
+		measurements []measurement
+		notReady     []schema.GroupVersionKind
+		want         aggregateReport
+	}{
+		"PerGVKAndGroup": {
+			reason: "The times-to-readiness should be aggregated per GVK and per group with the not-ready resources, and the deletions should not be aggregated.",
+			measurements: []measurement{
+				readyMeasurement("vpc-1", 10),
+				readyMeasurement("vpc-2", 30),
+				deletedMeasurement("vpc-1", 10, 5),
+				withKind(readyMeasurement("subnet-1", 20), "Subnet"),
+			},
+			notReady: []schema.GroupVersionKind{subnet},
+			want: aggregateReport{
+				GVKs: []apiStatistics{
+					{API: "ec2.aws.upbound.io/v1beta1/Subnet", Pending: 1, Statistics: common.Statistics{Count: 1, Min: 20, Max: 20, Mean: 20, P50: 20, P90: 20, P99: 20}},
+					{API: "ec2.aws.upbound.io/v1beta1/VPC", Statistics: common.Statistics{Count: 2, Min: 10, Max: 30, Mean: 20, P50: 10, P90: 30, P99: 30}},
+				},
+				Groups: []apiStatistics{
+					{API: "ec2.aws.upbound.io", Pending: 1, Statistics: common.Statistics{Count: 3, Min: 10, Max: 30, Mean: 20, P50: 20, P90: 30, P99: 30}},
+				},
+			},
+		},
+		"UnreadyReasons": {
+			reason: "The not-ready resources should be counted as pending with their reasons per GVK and per group.",
+			measurements: []measurement{
+				unreadyMeasurement("Subnet", &unreadyDetails{SyncedReason: "ReconcileError", LastEventReason: "CannotCreateExternalResource"}),
+				unreadyMeasurement("Subnet", &unreadyDetails{SyncedReason: "ReconcileError"}),
+				unreadyMeasurement("VPC", &unreadyDetails{ReadyReason: "Creating"}),
+			},
+			want: aggregateReport{
+				GVKs: []apiStatistics{
+					{API: "ec2.aws.upbound.io/v1beta1/Subnet", Pending: 2, Reasons: map[string]int{"Synced: ReconcileError": 2, "Event: CannotCreateExternalResource": 1}},
+					{API: "ec2.aws.upbound.io/v1beta1/VPC", Pending: 1, Reasons: map[string]int{"Ready: Creating": 1}},
+				},
+				Groups: []apiStatistics{
+					{API: "ec2.aws.upbound.io", Pending: 3, Reasons: map[string]int{"Synced: ReconcileError": 2, "Event: CannotCreateExternalResource": 1, "Ready: Creating": 1}},
+				},
+			},
+		},
+		"OnlyNotReady": {
+			reason:   "A GVK without any ready resources should be reported with its not-ready resources.",
+			notReady: []schema.GroupVersionKind{subnet, subnet},
+			want: aggregateReport{
+				GVKs:   []apiStatistics{{API: "ec2.aws.upbound.io/v1beta1/Subnet", Pending: 2}},
+				Groups: []apiStatistics{{API: "ec2.aws.upbound.io", Pending: 2}},
+			},
+		},
+		"Empty": {
+			reason: "An empty report should be returned without any measurements.",
+			want:   aggregateReport{GVKs: []apiStatistics{}, Groups: []apiStatistics{}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, err := newAggregator(outputJSON, io.Discard, metricTTR)
+			if err != nil {
+				t.Fatalf("newAggregator(...): unexpected error: %v", err)
+			}
+			for _, m := range tc.measurements {
+				if err := a.add(m); err != nil {
+					t.Fatalf("add(...): unexpected error: %v", err)
+				}
+			}
+			for _, gvk := range tc.notReady {
+				a.addPending(gvk)
+			}
+			if diff := cmp.Diff(tc.want, a.report()); diff != "" {
+				t.Errorf("\n%s\nreport(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAggregatorFlushText(t *testing.T) {
+	buff := &bytes.Buffer{}
+	a, err := newAggregator(outputText, buff, metricTTR)
+	if err != nil {
+		t.Fatalf("newAggregator(...): unexpected error: %v", err)
+	}
+	for _, m := range []measurement{readyMeasurement("vpc-1", 10), readyMeasurement("vpc-2", 30)} {
+		if err := a.add(m); err != nil {
+			t.Fatalf("add(...): unexpected error: %v", err)
+		}
+	}
+	if err := a.flush(); err != nil {
+		t.Fatalf("flush(): unexpected error: %v", err)
+	}
+	want := `GVK                             COUNT  NOT READY  MIN  MAX  MEAN  P50  P90  P99
+ec2.aws.upbound.io/v1beta1/VPC  2      0          10   30   20.0  10   30   30
+
+GROUP               COUNT  NOT READY  MIN  MAX  MEAN  P50  P90  P99
+ec2.aws.upbound.io  2      0          10   30   20.0  10   30   30
+`
+	if diff := cmp.Diff(want, buff.String()); diff != "" {
+		t.Errorf("flush(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestNewAggregator(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		format  string
+		wantErr bool
+	}{
+		"Text": {
+			reason: "The text format should be supported.",
+			format: outputText,
+		},
+		"CSV": {
+			reason:  "The CSV format should not be supported with the aggregated statistics.",
+			format:  outputCSV,
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newAggregator(tc.format, io.Discard, metricTTR)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nnewAggregator(...): unexpected error: %v", tc.reason, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/upbound/uptest/internal/common"
+)
+
+// regression is a time-to-readiness percentile of an API that has
+// regressed compared to the baseline.
+type regression struct {
+	// API is the group/version/kind of the API.
+	API string
+	// Percentile is the name of the percentile, e.g., p90.
+	Percentile string
+	// Baseline is the percentile in the baseline in seconds.
+	Baseline float64
+	// Current is the current percentile in seconds.
+	Current float64
+	// Change is the relative change of the percentile, e.g., 0.5 for 50%.
+	Change float64
+}
+
+// parseMaxRegression parses a maximum regression given as a percentage,
+// e.g., 20% or 20, into a fraction, e.g., 0.2.
+func parseMaxRegression(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid maximum regression %q: must be a percentage, e.g., 20%%", s)
+	}
+	if v < 0 {
+		return 0, errors.Errorf("invalid maximum regression %q: cannot be negative", s)
+	}
+	return v / 100, nil
+}
+
+// loadBaseline loads the baseline time-to-readiness statistics from the
+// specified JSON report, which is either a report of the aggregated
+// statistics generated with --aggregate or a report of the individual
+// measurements, which are then aggregated.
+func loadBaseline(path string) (aggregateReport, error) {
+	buff, err := os.ReadFile(path) //nolint:gosec // the baseline report is specified by the user
+	if err != nil {
+		return aggregateReport{}, errors.Wrapf(err, "failed to read the baseline report: %s", path)
+	}
+	buff = bytes.TrimSpace(buff)
+	if len(buff) > 0 && buff[0] == '[' {
+		var ms []measurement
+		if err := json.Unmarshal(buff, &ms); err != nil {
+			return aggregateReport{}, errors.Wrapf(err, "failed to unmarshal the baseline measurements: %s", path)
+		}
+		// the aggregator does not write anything unless flushed
+		agg, err := newAggregator(outputJSON, io.Discard, metricTTR)
+		if err != nil {
+			return aggregateReport{}, err
+		}
+		for i, m := range ms {
+			if err := validateMeasurement(m); err != nil {
+				return aggregateReport{}, errors.Wrapf(err, "invalid baseline measurement at index %d: %s", i, path)
+			}
+			if err := agg.add(m); err != nil {
+				return aggregateReport{}, err
+			}
+		}
+		return agg.report(), nil
+	}
+	var r aggregateReport
+	if err := json.Unmarshal(buff, &r); err != nil {
+		return aggregateReport{}, errors.Wrapf(err, "failed to unmarshal the baseline statistics: %s", path)
+	}
+	return r, nil
+}
+
+// validateMeasurement checks that the specified measurement has the fields
+// required by its event for the aggregation.
+func validateMeasurement(m measurement) error {
+	switch m.Event { //nolint:exhaustive // the deletions are not aggregated
+	case eventReady:
+		if m.ReadyTime == nil || m.TTRSeconds == nil {
+			return errors.New("a ready measurement must have readyTime and ttrSeconds")
+		}
+	case eventUnready:
+		if m.Unready == nil {
+			return errors.New("an unready measurement must have unready details")
+		}
+	}
+	return nil
+}
+
+// compareBaseline returns the p50, p90 and p99 time-to-readiness
+// percentiles of the APIs that have increased by more than the specified
+// fraction compared to the baseline. Only the APIs with measurements both
+// in the baseline and in the current statistics are compared. A percentile
+// of zero seconds in the baseline is not compared, as its relative change
+// is not defined.
+func compareBaseline(baseline, current aggregateReport, maxRegression float64) []regression {
+	base := make(map[string]common.Statistics, len(baseline.GVKs))
+	for _, s := range baseline.GVKs {
+		base[s.API] = s.Statistics
+	}
+	var regressions []regression
+	for _, s := range current.GVKs {
+		b, ok := base[s.API]
+		if !ok || b.Count == 0 || s.Count == 0 {
+			continue
+		}
+		for _, p := range []struct {
+			name          string
+			base, current float64
+		}{
+			{name: "p50", base: b.P50, current: s.P50},
+			{name: "p90", base: b.P90, current: s.P90},
+			{name: "p99", base: b.P99, current: s.P99},
+		} {
+			if p.base <= 0 {
+				continue
+			}
+			if change := (p.current - p.base) / p.base; change > maxRegression {
+				regressions = append(regressions, regression{
+					API:        s.API,
+					Percentile: p.name,
+					Baseline:   p.base,
+					Current:    p.current,
+					Change:     change,
+				})
+			}
+		}
+	}
+	return regressions
+}
+
+// printRegressions writes a table of the specified regressions.
+func printRegressions(w io.Writer, regressions []regression, maxRegression float64) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "%d time-to-readiness percentiles regressed by more than %s compared to the baseline:\n",
+		len(regressions), formatPercentage(maxRegression)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(tw, "GVK\tPERCENTILE\tBASELINE\tCURRENT\tCHANGE"); err != nil {
+		return err
+	}
+	for _, r := range regressions {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%.0f\t%.0f\t+%s\n", r.API, r.Percentile, r.Baseline, r.Current, formatPercentage(r.Change)); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func formatPercentage(f float64) string {
+	return strconv.FormatFloat(f*100, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadBaseline(t *testing.T) {
+	want, err := loadBaseline("testdata/baseline-aggregated.json")
+	if err != nil {
+		t.Fatalf("loadBaseline(...): unexpected error: %v", err)
+	}
+	if len(want.GVKs) != 2 || len(want.Groups) != 2 {
+		t.Fatalf("loadBaseline(...): expected 2 GVKs and 2 groups in the aggregated baseline, got %d and %d", len(want.GVKs), len(want.Groups))
+	}
+	got, err := loadBaseline("testdata/baseline-measurements.json")
+	if err != nil {
+		t.Fatalf("loadBaseline(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("loadBaseline(...): the aggregated measurements should equal the aggregated baseline: -want, +got:\n%s", diff)
+	}
+	if _, err := loadBaseline("testdata/missing.json"); err == nil {
+		t.Errorf("loadBaseline(...): expected an error for a missing baseline")
+	}
+}
+
+func TestLoadBaselineInvalidMeasurements(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   string
+	}{
+		"ReadyWithoutTTR": {
+			reason: "A ready measurement without the time-to-readiness should be rejected with its index.",
+			path:   "testdata/baseline-missing-ttr.json",
+			want:   "invalid baseline measurement at index 1: testdata/baseline-missing-ttr.json: a ready measurement must have readyTime and ttrSeconds",
+		},
+		"UnreadyWithoutDetails": {
+			reason: "An unready measurement without the details should be rejected with its index.",
+			path:   "testdata/baseline-missing-unready.json",
+			want:   "invalid baseline measurement at index 0: testdata/baseline-missing-unready.json: an unready measurement must have unready details",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadBaseline(tc.path)
+			if err == nil {
+				t.Fatalf("\n%s\nloadBaseline(%q): expected an error", tc.reason, tc.path)
+			}
+			if diff := cmp.Diff(tc.want, err.Error()); diff != "" {
+				t.Errorf("\n%s\nloadBaseline(%q): -want, +got:\n%s", tc.reason, tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	baseline, err := loadBaseline("testdata/baseline-measurements.json")
+	if err != nil {
+		t.Fatalf("loadBaseline(...): unexpected error: %v", err)
+	}
+	current, err := loadBaseline("testdata/current-aggregated.json")
+	if err != nil {
+		t.Fatalf("loadBaseline(...): unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		reason        string
+		current       aggregateReport
+		maxRegression float64
+		want          []regression
+	}{
+		"NoChange": {
+			reason:  "No regressions should be reported if the statistics equal the baseline.",
+			current: baseline,
+			want:    nil,
+		},
+		"Regressed": {
+			reason:        "The percentiles regressed by more than the maximum should be reported, but not the ones regressed by exactly the maximum or the new APIs.",
+			current:       current,
+			maxRegression: 0.2,
+			want: []regression{
+				{API: "ec2.aws.upbound.io/v1beta1/VPC", Percentile: "p90", Baseline: 40, Current: 60, Change: 0.5},
+				{API: "ec2.aws.upbound.io/v1beta1/VPC", Percentile: "p99", Baseline: 40, Current: 60, Change: 0.5},
+			},
+		},
+		"WithinMaximum": {
+			reason:        "No regressions should be reported if all the percentiles are within the maximum.",
+			current:       current,
+			maxRegression: 0.5,
+			want:          nil,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := compareBaseline(baseline, tc.current, tc.maxRegression)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncompareBaseline(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParseMaxRegression(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		s       string
+		want    float64
+		wantErr bool
+	}{
+		"Percentage": {
+			reason: "A percentage should be parsed into a fraction.",
+			s:      "20%",
+			want:   0.2,
+		},
+		"Number": {
+			reason: "A number without the percent sign should be parsed as a percentage.",
+			s:      "5",
+			want:   0.05,
+		},
+		"Negative": {
+			reason:  "A negative percentage should be rejected.",
+			s:       "-10%",
+			wantErr: true,
+		},
+		"Invalid": {
+			reason:  "A non-numeric value should be rejected.",
+			s:       "fast",
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseMaxRegression(tc.s)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nparseMaxRegression(%q): unexpected error: %v", tc.reason, tc.s, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nparseMaxRegression(%q): -want, +got:\n%s", tc.reason, tc.s, diff)
+			}
+		})
+	}
+}
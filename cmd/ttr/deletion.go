@@ -0,0 +1,110 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deletionWatcher tracks the deletion of the watched managed resources,
+// reporting each deleted resource as soon as it's removed. The informer
+// event handlers may run concurrently for different APIs.
+type deletionWatcher struct {
+	f  filters
+	o  *output
+	mu sync.Mutex
+	// deleting are the resources being deleted, with their deletion
+	// timestamps.
+	deleting map[resourceKey]time.Time
+	// deleted is the number of the resources reported as removed.
+	deleted int
+}
+
+func newDeletionWatcher(f filters, o *output) *deletionWatcher {
+	return &deletionWatcher{
+		f:        f,
+		o:        o,
+		deleting: make(map[resourceKey]time.Time),
+	}
+}
+
+// observe tracks the specified resource if it's being deleted.
+func (w *deletionWatcher) observe(_ context.Context, api managedAPI, obj any) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u.GetDeletionTimestamp() == nil || !w.f.match(api.gvk, u.GetNamespace(), u.GetName(), u.GetLabels()) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deleting[resourceKey{gvk: api.gvk, namespace: u.GetNamespace(), name: u.GetName()}] = u.GetDeletionTimestamp().Time
+}
+
+// forget reports the specified resource, whose removal has been observed
+// at the specified time.
+func (w *deletionWatcher) forget(ctx context.Context, api managedAPI, obj any, removed time.Time) {
+	// the removal time is not known if the deletion has been missed
+	observed := true
+	if t, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = t.Obj
+		observed = false
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	k := resourceKey{gvk: api.gvk, namespace: u.GetNamespace(), name: u.GetName()}
+	report := observed && u.GetDeletionTimestamp() != nil && w.f.match(api.gvk, u.GetNamespace(), u.GetName(), u.GetLabels())
+	w.mu.Lock()
+	delete(w.deleting, k)
+	if report {
+		w.deleted++
+	}
+	w.mu.Unlock()
+	if !report {
+		return
+	}
+	// the lock is not held while recording, which may look up
+	// the provider pod.
+	if err := w.o.recordDeletion(ctx, api, u, removed); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to report the deletion of %s: %v\n", k, err)
+	}
+}
+
+// summary prints the numbers of the resources that have been removed and
+// that are still being deleted, and the resources that are still being
+// deleted with the durations since their deletion. The resources that are
+// still being deleted are also recorded in the output.
+func (w *deletionWatcher) summary(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	keys := sortedKeys(w.deleting)
+	fmt.Fprintf(os.Stderr, "%d managed resources were deleted, %d are still being deleted\n", w.deleted, len(keys))
+	for _, k := range keys {
+		w.o.pending(k.gvk)
+		fmt.Fprintf(os.Stderr, "- %s (deleted for: %s)\n", k, now.Sub(w.deleting[k]).Round(time.Second))
+	}
+	if len(keys) > 0 {
+		return errors.Errorf("%d managed resources are still being deleted", len(keys))
+	}
+	return nil
+}
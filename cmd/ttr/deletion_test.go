@@ -0,0 +1,144 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/upbound/uptest/internal/common"
+)
+
+// deletingVPC returns a VPC, which is being deleted since testDeleted if
+// deleting is true.
+func deletingVPC(t *testing.T, name string, deleting bool) *unstructured.Unstructured {
+	t.Helper()
+	u := newManaged(t, name)
+	if deleting {
+		dt := metav1.NewTime(testDeleted)
+		u.SetDeletionTimestamp(&dt)
+	}
+	return &u
+}
+
+func TestDeletionWatcher(t *testing.T) {
+	removed := testDeleted.Add(30 * time.Second)
+	cases := map[string]struct {
+		reason     string
+		filters    []string
+		events     func(t *testing.T) []trackerEvent
+		want       aggregateReport
+		wantErr    bool
+		wantDelete int
+	}{
+		"Removed": {
+			reason: "A resource removed after being deleted should be reported with its time-to-deletion.",
+			events: func(t *testing.T) []trackerEvent {
+				return []trackerEvent{
+					{obj: deletingVPC(t, "vpc", true)},
+					{obj: deletingVPC(t, "vpc", true), removed: removed},
+				}
+			},
+			want: aggregateReport{
+				GVKs: []apiStatistics{
+					{API: "ec2.aws.upbound.io/v1beta1/VPC", Statistics: common.Statistics{Count: 1, Min: 30, Max: 30, Mean: 30, P50: 30, P90: 30, P99: 30}},
+				},
+				Groups: []apiStatistics{
+					{API: "ec2.aws.upbound.io", Statistics: common.Statistics{Count: 1, Min: 30, Max: 30, Mean: 30, P50: 30, P90: 30, P99: 30}},
+				},
+			},
+			wantDelete: 1,
+		},
+		"StillDeleting": {
+			reason: "A resource still being deleted should be counted as pending and an error should be returned.",
+			events: func(t *testing.T) []trackerEvent {
+				return []trackerEvent{{obj: deletingVPC(t, "vpc", true)}}
+			},
+			want: aggregateReport{
+				GVKs:   []apiStatistics{{API: "ec2.aws.upbound.io/v1beta1/VPC", Pending: 1}},
+				Groups: []apiStatistics{{API: "ec2.aws.upbound.io", Pending: 1}},
+			},
+			wantErr: true,
+		},
+		"RemovedWithoutDeletionTimestamp": {
+			reason: "A resource removed without a deletion timestamp should not be reported.",
+			events: func(t *testing.T) []trackerEvent {
+				return []trackerEvent{
+					{obj: deletingVPC(t, "vpc", false)},
+					{obj: deletingVPC(t, "vpc", false), removed: removed},
+				}
+			},
+			want: aggregateReport{GVKs: []apiStatistics{}, Groups: []apiStatistics{}},
+		},
+		"MissedRemoval": {
+			reason: "A resource whose removal has been missed should be neither reported nor pending.",
+			events: func(t *testing.T) []trackerEvent {
+				return []trackerEvent{
+					{obj: deletingVPC(t, "vpc", true)},
+					{obj: cache.DeletedFinalStateUnknown{Key: "vpc", Obj: deletingVPC(t, "vpc", true)}, removed: removed},
+				}
+			},
+			want: aggregateReport{GVKs: []apiStatistics{}, Groups: []apiStatistics{}},
+		},
+		"Filtered": {
+			reason:  "A resource not matching the filters should be neither tracked nor reported.",
+			filters: []string{"//Subnet/"},
+			events: func(t *testing.T) []trackerEvent {
+				return []trackerEvent{
+					{obj: deletingVPC(t, "vpc", true)},
+					{obj: deletingVPC(t, "vpc", true), removed: removed},
+				}
+			},
+			want: aggregateReport{GVKs: []apiStatistics{}, Groups: []apiStatistics{}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := getFilters(tc.filters...)
+			if err != nil {
+				t.Fatalf("getFilters(...): unexpected error: %v", err)
+			}
+			agg, err := newAggregator(outputText, io.Discard, metricTimeToDeletion)
+			if err != nil {
+				t.Fatalf("newAggregator(...): unexpected error: %v", err)
+			}
+			w := newDeletionWatcher(f, &output{r: agg, agg: agg})
+			for _, e := range tc.events(t) {
+				if e.removed.IsZero() {
+					w.observe(context.Background(), apiVPC, e.obj)
+					continue
+				}
+				w.forget(context.Background(), apiVPC, e.obj, e.removed)
+			}
+			err = w.summary(removed)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nsummary(...): unexpected error: %v", tc.reason, err)
+			}
+			if w.deleted != tc.wantDelete {
+				t.Errorf("\n%s\nsummary(...): got %d deleted resources, want %d", tc.reason, w.deleted, tc.wantDelete)
+			}
+			if diff := cmp.Diff(tc.want, agg.report()); diff != "" {
+				t.Errorf("\n%s\nreport(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
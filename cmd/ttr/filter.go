@@ -22,12 +22,20 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// reNamespace matches a valid namespace name, i.e., a DNS label.
+var reNamespace = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
 type filter struct {
-	gvk  schema.GroupVersionKind
-	name *regexp.Regexp
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      *regexp.Regexp
+	// selector is the label selector for the resources. Nil if the filter
+	// does not select resources by their labels.
+	selector labels.Selector
 }
 
 func (f filter) matchGVK(gvk schema.GroupVersionKind) bool {
@@ -38,23 +46,52 @@ func (f filter) matchGVK(gvk schema.GroupVersionKind) bool {
 
 type filters []*filter
 
-func (f filters) match(gvk schema.GroupVersionKind, name string) bool {
+func (f filter) matchResource(namespace, name string, l labels.Set) bool {
+	return (len(f.namespace) == 0 || f.namespace == namespace) &&
+		(f.name == nil || f.name.MatchString(name)) &&
+		(f.selector == nil || f.selector.Matches(l))
+}
+
+// match returns true if any of the filters matches the specified API and,
+// if the name is not empty, the specified resource of that API with
+// the specified labels.
+func (f filters) match(gvk schema.GroupVersionKind, namespace, name string, l map[string]string) bool {
 	if len(f) == 0 {
 		return true
 	}
 	for _, e := range f {
-		if e.matchGVK(gvk) && (len(name) == 0 || e.name == nil || e.name.MatchString(name)) {
+		if e.matchGVK(gvk) && (len(name) == 0 || e.matchResource(namespace, name, l)) {
 			return true
 		}
 	}
 	return false
 }
 
+// parseFilter parses a filter expression of the form
+// [group]/[version]/[kind]/[name regex][/[namespace][/label selector]].
 func parseFilter(f string) (*filter, error) {
-	tokens := strings.Split(f, "/")
-	if len(tokens) != 4 {
+	// the optional label selector segment may itself contain slashes,
+	// e.g., in crossplane.io/claim-name=example. The name regex cannot
+	// contain slashes as the following segment is the namespace.
+	tokens := strings.SplitN(f, "/", 6)
+	if len(tokens) < 4 {
 		return nil, errors.Errorf("invalid filter string: %s", f)
 	}
+	var ns string
+	if len(tokens) > 4 {
+		ns = tokens[4]
+		if len(ns) != 0 && !reNamespace.MatchString(ns) {
+			return nil, errors.Errorf("invalid namespace %q in the filter string: %s", ns, f)
+		}
+	}
+	var sel labels.Selector
+	if len(tokens) == 6 && len(tokens[5]) != 0 {
+		s, err := labels.Parse(tokens[5])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label selector: %s", tokens[5])
+		}
+		sel = s
+	}
 	var re *regexp.Regexp
 	if len(tokens[3]) != 0 {
 		r, err := regexp.Compile(tokens[3])
@@ -69,10 +106,28 @@ func parseFilter(f string) (*filter, error) {
 			Version: tokens[1],
 			Kind:    tokens[2],
 		},
-		name: re,
+		namespace: ns,
+		name:      re,
+		selector:  sel,
 	}, nil
 }
 
+// checkNamespace returns an error if any of the filters selects a namespace
+// other than the specified namespace, which is the only namespace whose
+// resources are listed. An empty namespace means all namespaces.
+func (f filters) checkNamespace(ns string) error {
+	if ns == "" {
+		return nil
+	}
+	for _, e := range f {
+		if e.namespace != "" && e.namespace != ns {
+			return errors.Errorf("a filter selects the namespace %q, but only the resources in the namespace %q are listed: specify --namespace %s or --all-namespaces",
+				e.namespace, ns, e.namespace)
+		}
+	}
+	return nil
+}
+
 func getFilters(f ...string) (filters, error) {
 	result := make(filters, 0, len(f))
 	for _, s := range f {
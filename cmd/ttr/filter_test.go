@@ -0,0 +1,219 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// parsedFilter is the comparable representation of a filter.
+type parsedFilter struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+	Selector  string
+}
+
+func toParsedFilter(f *filter) *parsedFilter {
+	if f == nil {
+		return nil
+	}
+	p := &parsedFilter{GVK: f.gvk, Namespace: f.namespace}
+	if f.name != nil {
+		p.Name = f.name.String()
+	}
+	if f.selector != nil {
+		p.Selector = f.selector.String()
+	}
+	return p
+}
+
+func TestParseFilter(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		f       string
+		want    *parsedFilter
+		wantErr bool
+	}{
+		"GVKAndName": {
+			reason: "The group, version, kind and name regex should be parsed.",
+			f:      "cognitoidp.aws.upbound.io/v1beta1/UserPool/example-.*",
+			want: &parsedFilter{
+				GVK:  schema.GroupVersionKind{Group: "cognitoidp.aws.upbound.io", Version: "v1beta1", Kind: "UserPool"},
+				Name: "example-.*",
+			},
+		},
+		"Namespace": {
+			reason: "The fifth segment should be parsed as the namespace.",
+			f:      "///example/test",
+			want:   &parsedFilter{Namespace: "test", Name: "example"},
+		},
+		"NamespaceOnly": {
+			reason: "A namespace without a name regex should select all the resources in the namespace.",
+			f:      "////test",
+			want:   &parsedFilter{Namespace: "test"},
+		},
+		"RegexWithColon": {
+			reason: "A name regex containing a colon should be kept as is.",
+			f:      "///foo:bar",
+			want:   &parsedFilter{Name: "foo:bar"},
+		},
+		"RegexWithSlash": {
+			reason:  "A name regex containing a slash should be rejected as the part after the slash is not a valid namespace.",
+			f:       "///foo/bar.*",
+			wantErr: true,
+		},
+		"InvalidNamespace": {
+			reason:  "An invalid namespace should be rejected.",
+			f:       "////Test",
+			wantErr: true,
+		},
+		"LabelSelectorWithSlash": {
+			reason: "A label selector containing slashes should be parsed as a whole.",
+			f:      "ec2.aws.upbound.io///vpc-.*/test/crossplane.io/claim-name=example,test-run in (1,2)",
+			want: &parsedFilter{
+				GVK:       schema.GroupVersionKind{Group: "ec2.aws.upbound.io"},
+				Namespace: "test",
+				Name:      "vpc-.*",
+				Selector:  "crossplane.io/claim-name=example,test-run in (1,2)",
+			},
+		},
+		"EmptyNamespaceAndLabelSelector": {
+			reason: "Empty namespace and label selector segments should select the resources in all namespaces regardless of their labels.",
+			f:      "///example//",
+			want:   &parsedFilter{Name: "example"},
+		},
+		"TooFewSegments": {
+			reason:  "A filter with less than four segments should be rejected.",
+			f:       "//UserPool",
+			wantErr: true,
+		},
+		"InvalidRegex": {
+			reason:  "An invalid name regex should be rejected.",
+			f:       "///example-(",
+			wantErr: true,
+		},
+		"InvalidLabelSelector": {
+			reason:  "An invalid label selector should be rejected.",
+			f:       "/////test-run in (1",
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseFilter(tc.f)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nparseFilter(%q): unexpected error: %v", tc.reason, tc.f, err)
+			}
+			if diff := cmp.Diff(tc.want, toParsedFilter(got)); diff != "" {
+				t.Errorf("\n%s\nparseFilter(%q): -want, +got:\n%s", tc.reason, tc.f, diff)
+			}
+		})
+	}
+}
+
+func TestCheckNamespace(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		filters []string
+		ns      string
+		wantErr bool
+	}{
+		"SameNamespace": {
+			reason:  "A filter selecting the listed namespace should be accepted.",
+			filters: []string{"///example/test", "//VPC/"},
+			ns:      "test",
+		},
+		"AllNamespaces": {
+			reason:  "A filter selecting any namespace should be accepted if all the namespaces are listed.",
+			filters: []string{"///example/test"},
+		},
+		"OtherNamespace": {
+			reason:  "A filter selecting a namespace that is not listed should be rejected, as it can never match.",
+			filters: []string{"///example/test"},
+			ns:      "default",
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := getFilters(tc.filters...)
+			if err != nil {
+				t.Fatalf("getFilters(...): unexpected error: %v", err)
+			}
+			if err := f.checkNamespace(tc.ns); (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\ncheckNamespace(%q): unexpected error: %v", tc.reason, tc.ns, err)
+			}
+		})
+	}
+}
+
+func TestFiltersMatch(t *testing.T) {
+	f, err := getFilters("ec2.aws.upbound.io//VPC/vpc-.*/test/test-run=1")
+	if err != nil {
+		t.Fatalf("getFilters(...): unexpected error: %v", err)
+	}
+	vpc := schema.GroupVersionKind{Group: "ec2.aws.upbound.io", Version: "v1beta1", Kind: "VPC"}
+	cases := map[string]struct {
+		reason    string
+		gvk       schema.GroupVersionKind
+		namespace string
+		name      string
+		labels    map[string]string
+		want      bool
+	}{
+		"API": {
+			reason: "An API should match without a resource name.",
+			gvk:    vpc,
+			want:   true,
+		},
+		"OtherAPI": {
+			reason: "An API of another kind should not match.",
+			gvk:    schema.GroupVersionKind{Group: "ec2.aws.upbound.io", Version: "v1beta1", Kind: "Subnet"},
+		},
+		"Resource": {
+			reason:    "A resource with the namespace, name and labels should match.",
+			gvk:       vpc,
+			namespace: "test",
+			name:      "vpc-1",
+			labels:    map[string]string{"test-run": "1"},
+			want:      true,
+		},
+		"OtherNamespace": {
+			reason:    "A resource in another namespace should not match.",
+			gvk:       vpc,
+			namespace: "default",
+			name:      "vpc-1",
+			labels:    map[string]string{"test-run": "1"},
+		},
+		"OtherLabels": {
+			reason:    "A resource without the selected labels should not match.",
+			gvk:       vpc,
+			namespace: "test",
+			name:      "vpc-1",
+			labels:    map[string]string{"test-run": "2"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := f.match(tc.gvk, tc.namespace, tc.name, tc.labels); got != tc.want {
+				t.Errorf("\n%s\nmatch(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
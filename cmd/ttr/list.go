@@ -0,0 +1,152 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// lister lists the managed resources of a set of APIs and reports them.
+// The resources of an API are listed page by page, and the APIs are listed
+// by a bounded number of concurrent workers.
+type lister struct {
+	dyn dynamic.Interface
+	f   filters
+	// ns is the namespace of the namespaced APIs' resources.
+	ns string
+	// lo are the list options with the selectors and the page size.
+	lo metav1.ListOptions
+	o  *output
+	// workers is the maximum number of APIs listed concurrently.
+	workers int
+	// includeUnready enables reporting the not-ready resources.
+	includeUnready bool
+	// eventReasons are the reasons of the last events recorded for
+	// the resources, indexed by their UIDs. Only listed with
+	// includeUnready.
+	eventReasons map[types.UID]string
+}
+
+// run lists and reports the managed resources of the specified APIs. If
+// listing fails for any of the APIs, the APIs being listed are canceled,
+// the remaining APIs are not listed, and the first failure is returned.
+func (l *lister) run(ctx context.Context, apis []managedAPI) error {
+	if l.includeUnready {
+		// the events are looked up on a best effort basis: the not-ready
+		// resources are still reported if the events cannot be listed.
+		var err error
+		if l.eventReasons, err = lastEventReasons(ctx, l.dyn, l.lo.Limit); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: the last event reasons of the not-ready resources will not be reported: %v\n", err)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var firstErr error
+	jobs := make(chan managedAPI)
+	var wg sync.WaitGroup
+	for range min(l.workers, len(apis)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for api := range jobs {
+				if err := l.listAPI(ctx, api); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+	for _, api := range apis {
+		select {
+		case jobs <- api:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return errors.Wrap(ctx.Err(), "failed to list the managed resources")
+}
+
+// listAPI lists and reports the managed resources of the specified API,
+// following the continue tokens of the pages.
+func (l *lister) listAPI(ctx context.Context, api managedAPI) error {
+	ri := l.dyn.Resource(api.gvr).Namespace(api.namespace(l.ns))
+	lo := l.lo
+	for {
+		ul, err := ri.List(ctx, lo)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list resources with GVR: %s", api.gvr.String())
+		}
+		for _, u := range ul.Items {
+			if !l.f.match(api.gvk, u.GetNamespace(), u.GetName(), u.GetLabels()) {
+				continue
+			}
+			ready, err := l.o.record(ctx, api, &u)
+			if err != nil {
+				return err
+			}
+			switch {
+			case ready:
+			case l.includeUnready:
+				if err := l.o.recordUnready(ctx, api, &u, l.eventReasons[u.GetUID()], time.Now()); err != nil {
+					return err
+				}
+			default:
+				l.o.pending(api.gvk)
+			}
+		}
+		lo.Continue = ul.GetContinue()
+		if lo.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// warnDiscoveryFailures prints a warning for each API group version that
+// could not be discovered. The managed resources of those API group
+// versions are not reported.
+func warnDiscoveryFailures(err *discovery.ErrGroupDiscoveryFailed) {
+	gvs := make([]schema.GroupVersion, 0, len(err.Groups))
+	for gv := range err.Groups {
+		gvs = append(gvs, gv)
+	}
+	sort.Slice(gvs, func(i, j int) bool {
+		return gvs[i].String() < gvs[j].String()
+	})
+	for _, gv := range gvs {
+		fmt.Fprintf(os.Stderr, "warning: failed to discover the API group version %s, its managed resources will not be reported: %v\n", gv, err.Groups[gv])
+	}
+}
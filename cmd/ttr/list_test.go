@@ -0,0 +1,178 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var apiSubnet = managedAPI{
+	gvr:        schema.GroupVersionResource{Group: "ec2.m.aws.upbound.io", Version: "v1beta1", Resource: "subnets"},
+	gvk:        schema.GroupVersionKind{Group: "ec2.m.aws.upbound.io", Version: "v1beta1", Kind: "Subnet"},
+	namespaced: true,
+}
+
+// pagedVPCs returns a list reactor serving the specified pages of VPCs
+// in order, with a continue token on each page but the last one.
+func pagedVPCs(t *testing.T, pages ...[]string) k8stesting.ReactionFunc {
+	t.Helper()
+	var served int
+	return func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		if served == len(pages) {
+			return true, nil, errors.New("no more pages")
+		}
+		ul := &unstructured.UnstructuredList{}
+		ul.SetAPIVersion("ec2.aws.upbound.io/v1beta1")
+		ul.SetKind("VPCList")
+		for _, name := range pages[served] {
+			ul.Items = append(ul.Items, newManaged(t, name, condition(xpv1.TypeSynced, corev1.ConditionTrue, 10), condition(xpv1.TypeReady, corev1.ConditionTrue, 30)))
+		}
+		served++
+		if served < len(pages) {
+			ul.SetContinue("page-2")
+		}
+		return true, ul, nil
+	}
+}
+
+func TestListerRun(t *testing.T) {
+	errBoom := errors.New("boom")
+	cases := map[string]struct {
+		reason    string
+		apis      []managedAPI
+		reactors  map[string]k8stesting.ReactionFunc
+		want      []string
+		wantLists int
+		wantErr   error
+	}{
+		"Paginated": {
+			reason: "The resources of all the pages of an API should be reported by following the continue tokens.",
+			apis:   []managedAPI{apiVPC},
+			reactors: map[string]k8stesting.ReactionFunc{
+				"vpcs": pagedVPCs(t, []string{"vpc-1", "vpc-2"}, []string{"vpc-3"}),
+			},
+			want:      []string{"vpc-1", "vpc-2", "vpc-3"},
+			wantLists: 2,
+		},
+		"ListError": {
+			reason: "An error should be returned if listing the resources of any of the APIs fails.",
+			apis:   []managedAPI{apiVPC, apiSubnet},
+			reactors: map[string]k8stesting.ReactionFunc{
+				"vpcs": pagedVPCs(t, []string{"vpc-1"}),
+				"subnets": func(_ k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errBoom
+				},
+			},
+			want:      []string{"vpc-1"},
+			wantLists: 2,
+			wantErr:   errBoom,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{apiVPC.gvr: "VPCList", apiSubnet.gvr: "SubnetList"})
+			for resource, fn := range tc.reactors {
+				dyn.PrependReactor("list", resource, fn)
+			}
+			r := &structuredReporter{}
+			l := &lister{dyn: dyn, o: &output{r: r}, workers: 1, lo: metav1.ListOptions{Limit: 2}}
+			err := l.run(context.Background(), tc.apis)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("\n%s\nrun(...): got error %v, want %v", tc.reason, err, tc.wantErr)
+			}
+			got := make([]string, 0, len(r.measurements))
+			for _, m := range r.measurements {
+				got = append(got, m.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nrun(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if got := len(dyn.Actions()); got != tc.wantLists {
+				t.Errorf("\n%s\nrun(...): got %d list requests, want %d", tc.reason, got, tc.wantLists)
+			}
+		})
+	}
+}
+
+func TestListerSelectors(t *testing.T) {
+	readyVPC := func(name string, l map[string]string) runtime.Object {
+		u := newManaged(t, name, condition(xpv1.TypeSynced, corev1.ConditionTrue, 10), condition(xpv1.TypeReady, corev1.ConditionTrue, 30))
+		u.SetLabels(l)
+		return &u
+	}
+	objs := []runtime.Object{
+		readyVPC("vpc-1", map[string]string{"test-run": "1"}),
+		readyVPC("vpc-2", map[string]string{"test-run": "2"}),
+		readyVPC("vpc-3", map[string]string{"test-run": "1", "team": "a"}),
+	}
+	cases := map[string]struct {
+		reason   string
+		selector string
+		filters  []string
+		want     []string
+	}{
+		"LabelSelector": {
+			reason:   "Only the resources selected by the label selector should be reported.",
+			selector: "test-run=1",
+			want:     []string{"vpc-1", "vpc-3"},
+		},
+		"FilterLabelSelector": {
+			reason:  "Only the resources matching the label selector of the filter should be reported.",
+			filters: []string{"/////team=a"},
+			want:    []string{"vpc-3"},
+		},
+		"SetBasedSelectors": {
+			reason:   "The label selector and the filter's label selector should both select the reported resources.",
+			selector: "test-run in (1,2)",
+			filters:  []string{"/////!team"},
+			want:     []string{"vpc-1", "vpc-2"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := getFilters(tc.filters...)
+			if err != nil {
+				t.Fatalf("getFilters(...): unexpected error: %v", err)
+			}
+			dyn := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{apiVPC.gvr: "VPCList"}, objs...)
+			r := &structuredReporter{}
+			l := &lister{dyn: dyn, f: f, o: &output{r: r}, workers: 1, lo: metav1.ListOptions{LabelSelector: tc.selector}}
+			if err := l.run(context.Background(), []managedAPI{apiVPC}); err != nil {
+				t.Fatalf("\n%s\nrun(...): unexpected error: %v", tc.reason, err)
+			}
+			got := make([]string, 0, len(r.measurements))
+			for _, m := range r.measurements {
+				got = append(got, m.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nrun(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
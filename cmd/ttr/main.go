@@ -18,17 +18,22 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/v2/pkg/fieldpath"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
@@ -41,61 +46,371 @@ import (
 // - ttr -f //UserPool/ -f //VPC/ -> Report all UserPool and VPC resources
 // - ttr -f cognitoidp.aws.upbound.io/// -> Report all resources in the group
 // - ttr -f ///example-.* -> Report all resources with names prefixed by example-
+// - ttr -n test -f ///example/test -> Report the namespaced resources named example in the test namespace
+// - ttr -A -> Report the namespaced resources in all namespaces
+// - ttr -l crossplane.io/claim-name=example -> Report the resources with the label
+// - ttr -f ec2.aws.upbound.io/////test-run=1 -f ec2.aws.upbound.io/////test-run=2 -> Report the resources of two test runs
+// - ttr --watch --timeout 30m -> Report the resources as they become ready
+// - ttr -o prom > ttr.prom -> Report in the Prometheus text exposition format
+// - ttr --aggregate -> Report the statistics per GVK and per group
+// - ttr --provider provider-aws-ec2 -> Report the resources of a single provider of a family
+// - ttr delete-watch --timeout 30m -> Report the time-to-deletion statistics per GVK and per group
 func main() {
 	cf := genericclioptions.NewConfigFlags(true)
-	var filters []string
+	opts := &options{}
 	cmd := &cobra.Command{
 		Use:          "ttr",
 		Short:        "Reports the time-to-readiness measurements for a subset of the managed resources in a Kubernetes cluster",
 		Example:      "ttr --kubeconfig=./kubeconfig",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return report(cf, filters)
+			return report(cmd.Context(), cf, opts)
 		},
 	}
-	cmd.Flags().StringArrayVarP(&filters, "filters", "f", nil,
-		"Zero or more filter expressions each with the following syntax: [group]/[version]/[kind]/[name regex]. Can be repeated. "+
-			"Filters managed resources with the specified APIs and names. Missing entries should be specified as empty strings.")
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete-watch",
+		Short: "Watches the managed resources and reports the time-to-deletion statistics per GVK and per group",
+		Long: "Watches the managed resources and measures the duration from the deletion of each deleted resource until it's removed, " +
+			"i.e., until its finalizers are removed. The count, min, max, mean, p50, p90 and p99 time-to-deletion and the count of " +
+			"the resources still being deleted are reported per GVK and per group when the watch ends.",
+		Example:      "ttr delete-watch --timeout 30m -f ec2.aws.upbound.io///",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteWatch(cmd.Context(), cf, opts)
+		},
+	})
+	pf := cmd.PersistentFlags()
+	pf.StringArrayVarP(&opts.filters, "filters", "f", nil,
+		"Zero or more filter expressions each with the following syntax: [group]/[version]/[kind]/[name regex][/[namespace][/label selector]]. Can be repeated. "+
+			"Filters managed resources with the specified APIs, names, namespaces and labels. Missing entries should be specified as empty strings. "+
+			"The name regex cannot contain slashes. "+
+			"A filter's namespace must be the listed namespace, i.e., the one specified with --namespace, unless --all-namespaces is specified.")
+	pf.StringVar(&opts.category, "category", "managed",
+		"API category of the managed resource kinds.")
+	pf.StringVar(&opts.provider, "provider", "",
+		"Only report the managed resources whose CRDs are owned by the revisions of the specified provider package, e.g., provider-aws-ec2, "+
+			"or by the specified provider revision. Useful for measuring a single provider of a family on a shared cluster.")
+	pf.StringVarP(&opts.selector, "selector", "l", "",
+		"Label selector for the managed resources, e.g., crossplane.io/claim-name=example. Applied when listing or watching the resources.")
+	pf.StringVar(&opts.fieldSelector, "field-selector", "",
+		"Field selector for the managed resources, e.g., metadata.name=example. Applied when listing or watching the resources.")
+	pf.BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false,
+		"Report the namespaced managed resources in all namespaces. Otherwise, only the namespaced managed resources in the namespace "+
+			"specified with --namespace, or in the namespace of the current context, are reported. Cluster-scoped managed resources are always reported.")
+	pf.DurationVar(&opts.timeout, "timeout", 0,
+		"Overall duration of the watch. Zero means watching until interrupted. Only valid with --watch or the delete-watch command.")
+	pf.StringVarP(&opts.output, "output", "o", outputText,
+		"Output format. One of: text, json, yaml, csv, prom. The text format reports a group/version/kind/[namespace/]name:seconds line per resource. "+
+			"The other formats also include the namespace, creation time, Ready transition time, Synced status and provider pod of each resource, "+
+			"and the durations of the creation-to-Synced, Synced-to-Ready, creation-to-LastAsyncOperation and, in the watch mode, deletion-to-finalizer-removal phases. "+
+			"The prom format can be consumed by the Prometheus node exporter's textfile collector. "+
+			"Only the text, json and yaml formats are supported with --aggregate and the delete-watch command.")
+	cmd.Flags().BoolVar(&opts.watch, "watch", false,
+		"Watch the managed resources and report each resource as soon as it becomes ready, instead of reporting the already ready resources once. "+
+			"The resources that never become ready are reported when the watch ends. "+
+			"The deleted resources are also reported with the duration from their deletion until their finalizers are removed.")
+	cmd.Flags().BoolVar(&opts.aggregate, "aggregate", false,
+		"Report the count, min, max, mean, p50, p90 and p99 time-to-readiness and the count of the not-ready resources per GVK and per group, "+
+			"instead of the individual measurements.")
+	cmd.Flags().Int64Var(&opts.chunkSize, "chunk-size", 500,
+		"Maximum number of managed resources to list per request. The resources of an API are listed page by page. Zero disables the pagination.")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 4,
+		"Maximum number of APIs whose managed resources are listed concurrently.")
+	cmd.Flags().BoolVar(&opts.includeUnready, "include-unready", false,
+		"Also report the managed resources that are not ready, with their ages, the reasons and messages of their Ready and Synced conditions, "+
+			"and the reasons of their last events. The numbers of the not-ready resources per GVK and reason are reported to the standard error, "+
+			"or with the statistics if --aggregate is specified.")
+	cmd.Flags().StringVar(&opts.baseline, "baseline", "",
+		"Path of an earlier JSON report, generated either with -o json or with --aggregate -o json, to compare the p50, p90 and p99 time-to-readiness per GVK against. "+
+			"ttr exits with a non-zero code if any of the percentiles has regressed by more than --max-regression.")
+	cmd.Flags().StringVar(&opts.maxRegression, "max-regression", "10%",
+		"Maximum allowed regression of the time-to-readiness percentiles compared to the baseline, e.g., 20%. Only used with --baseline.")
 	// add common Kubernetes client configuration flags
-	cf.AddFlags(cmd.Flags())
-	if err := cmd.Execute(); err != nil {
+	cf.AddFlags(pf)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := cmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
-func report(cf *genericclioptions.ConfigFlags, filters []string) error {
+// options are the command-line options of ttr.
+type options struct {
+	// filters are the filter expressions for the managed resources.
+	filters []string
+	// category is the API category of the managed resources.
+	category string
+	// provider is the name of the provider package or revision whose
+	// managed resources are reported.
+	provider string
+	// selector is the label selector for the managed resources.
+	selector string
+	// fieldSelector is the field selector for the managed resources.
+	fieldSelector string
+	// allNamespaces enables reporting the namespaced resources in
+	// all namespaces.
+	allNamespaces bool
+	// watch enables reporting the resources as they become ready.
+	watch bool
+	// timeout is the overall duration of the watch.
+	timeout time.Duration
+	// output is the output format of the measurements.
+	output string
+	// aggregate enables reporting the aggregated statistics instead of
+	// the individual measurements.
+	aggregate bool
+	// chunkSize is the maximum number of resources to list per request.
+	chunkSize int64
+	// concurrency is the maximum number of APIs listed concurrently.
+	concurrency int
+	// includeUnready enables reporting the not-ready resources.
+	includeUnready bool
+	// baseline is the path of the JSON report to compare the
+	// time-to-readiness percentiles against.
+	baseline string
+	// maxRegression is the maximum allowed regression of
+	// the time-to-readiness percentiles compared to the baseline.
+	maxRegression string
+}
+
+// validate checks the options of the report command.
+func (o *options) validate() error {
+	if o.timeout != 0 && !o.watch {
+		return errors.New("--timeout can only be specified with --watch")
+	}
+	if o.chunkSize < 0 {
+		return errors.New("--chunk-size cannot be negative")
+	}
+	if o.concurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+	if o.includeUnready && o.watch {
+		return errors.New("--include-unready cannot be specified with --watch, which reports the resources that never become ready when the watch ends")
+	}
+	_, err := parseMaxRegression(o.maxRegression)
+	return err
+}
+
+// tweakListOptions sets the label and field selectors of the specified
+// list options.
+func (o *options) tweakListOptions(lo *metav1.ListOptions) {
+	lo.LabelSelector = o.selector
+	lo.FieldSelector = o.fieldSelector
+}
+
+// cluster is the connection to the cluster with the managed resource APIs
+// and the namespace selected with the command-line options.
+type cluster struct {
+	dyn  dynamic.Interface
+	apis []managedAPI
+	f    filters
+	ns   string
+}
+
+func connect(ctx context.Context, cf *genericclioptions.ConfigFlags, opts *options) (*cluster, error) {
+	if opts.category == "" {
+		return nil, errors.New("--category cannot be empty")
+	}
+	if _, err := labels.Parse(opts.selector); err != nil {
+		return nil, errors.Wrap(err, "invalid label selector")
+	}
+	if _, err := fields.ParseSelector(opts.fieldSelector); err != nil {
+		return nil, errors.Wrap(err, "invalid field selector")
+	}
+	cl := &cluster{ns: metav1.NamespaceAll}
+	var err error
+	if !opts.allNamespaces {
+		cl.ns, _, err = cf.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to determine the namespace")
+		}
+	}
 	dc, err := cf.ToDiscoveryClient()
 	if err != nil {
-		return errors.Wrap(err, "failed to initialize the Kubernetes discovery client")
+		return nil, errors.Wrap(err, "failed to initialize the Kubernetes discovery client")
 	}
 	c, err := cf.ToRESTConfig()
 	if err != nil {
-		return errors.Wrap(err, "failed to get REST config for the cluster")
+		return nil, errors.Wrap(err, "failed to get REST config for the cluster")
 	}
-	dyn, err := dynamic.NewForConfig(c)
+	cl.dyn, err = dynamic.NewForConfig(c)
 	if err != nil {
-		return errors.Wrap(err, "failed to initialize a dynamic Kubernetes client")
+		return nil, errors.Wrap(err, "failed to initialize a dynamic Kubernetes client")
 	}
-	_, rlList, err := dc.ServerGroupsAndResources()
+	cl.f, err = getFilters(opts.filters...)
 	if err != nil {
-		return errors.Wrap(err, "failed to discover the API resource list")
+		return nil, errors.Wrap(err, "failed to convert filter expression")
+	}
+	if err := cl.f.checkNamespace(cl.ns); err != nil {
+		return nil, err
 	}
-	f, err := getFilters(filters...)
+	cl.apis, err = discoverAPIs(dc, cl.f, opts.category)
 	if err != nil {
-		return errors.Wrap(err, "failed to convert filter expression")
+		return nil, err
+	}
+	if opts.provider != "" {
+		owned, err := providerResources(ctx, cl.dyn, opts.provider)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to collect the APIs of the provider %q", opts.provider)
+		}
+		cl.apis = ownedAPIs(cl.apis, owned)
 	}
-	return errors.Wrap(reportOnAPIs(rlList, f, dyn), "failed to report on the available APIs")
+	return cl, nil
 }
 
-func reportOnAPIs(rlList []*metav1.APIResourceList, f filters, dyn dynamic.Interface) error { //nolint:gocyclo // should we break this?
+func report(ctx context.Context, cf *genericclioptions.ConfigFlags, opts *options) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	o, stats, err := newOutput(opts)
+	if err != nil {
+		return err
+	}
+	var baseline aggregateReport
+	if opts.baseline != "" {
+		if baseline, err = loadBaseline(opts.baseline); err != nil {
+			return err
+		}
+	}
+	cl, err := connect(ctx, cf, opts)
+	if err != nil {
+		return err
+	}
+	if opts.output != outputText && !opts.aggregate {
+		o.pods = newProviderPods(cl.dyn)
+	}
+	if opts.watch {
+		err = errors.Wrap(watchAPIs(ctx, cl.apis, newWatcher(cl.f, o), cl.dyn, cl.ns, opts.tweakListOptions, opts.timeout), "failed to watch the managed resources")
+	} else {
+		l := &lister{dyn: cl.dyn, f: cl.f, ns: cl.ns, o: o, workers: opts.concurrency, includeUnready: opts.includeUnready}
+		opts.tweakListOptions(&l.lo)
+		l.lo.Limit = opts.chunkSize
+		err = errors.Wrap(l.run(ctx, cl.apis), "failed to report on the available APIs")
+	}
+	// flush the measurements collected so far even if reporting has failed
+	if fErr := o.r.flush(); fErr != nil && err == nil {
+		err = errors.Wrap(fErr, "failed to write the measurements")
+	}
+	if err != nil || stats == nil {
+		return err
+	}
+	r := stats.report()
+	if opts.includeUnready && !opts.aggregate && r.hasReasons() {
+		if err := renderReasons(os.Stderr, r.GVKs); err != nil {
+			return errors.Wrap(err, "failed to write the reasons of the not-ready resources")
+		}
+	}
+	if opts.baseline == "" {
+		return nil
+	}
+	return checkBaseline(baseline, r, opts.maxRegression)
+}
+
+// newOutput returns the output of the report command, and the aggregator
+// of the time-to-readiness statistics, which is nil unless the statistics
+// are reported, compared against a baseline, or the reasons of
+// the not-ready resources are summarized.
+func newOutput(opts *options) (*output, *aggregator, error) {
+	o := &output{}
+	var err error
+	if opts.aggregate {
+		o.agg, err = newAggregator(opts.output, os.Stdout, metricTTR)
+		o.r = o.agg
+		return o, o.agg, err
+	}
+	if o.r, err = newReporter(opts.output, os.Stdout); err != nil || (opts.baseline == "" && !opts.includeUnready) {
+		return o, nil, err
+	}
+	// the statistics compared against the baseline are not written
+	stats, err := newAggregator(outputText, io.Discard, metricTTR)
+	if err != nil {
+		return nil, nil, err
+	}
+	o.r = teeReporter{o.r, stats}
+	return o, stats, nil
+}
+
+// checkBaseline compares the current time-to-readiness statistics against
+// the baseline and returns an error if any of the percentiles has
+// regressed by more than the specified maximum.
+func checkBaseline(baseline, current aggregateReport, maxRegression string) error {
+	maxR, err := parseMaxRegression(maxRegression)
+	if err != nil {
+		return err
+	}
+	regressions := compareBaseline(baseline, current, maxR)
+	if len(regressions) == 0 {
+		return nil
+	}
+	if err := printRegressions(os.Stderr, regressions, maxR); err != nil {
+		return errors.Wrap(err, "failed to write the regressions")
+	}
+	return errors.Errorf("%d time-to-readiness percentiles regressed compared to the baseline", len(regressions))
+}
+
+// deleteWatch watches the deletion of the managed resources and reports
+// the time-to-deletion statistics when the watch ends.
+func deleteWatch(ctx context.Context, cf *genericclioptions.ConfigFlags, opts *options) error {
+	agg, err := newAggregator(opts.output, os.Stdout, metricTimeToDeletion)
+	if err != nil {
+		return err
+	}
+	cl, err := connect(ctx, cf, opts)
+	if err != nil {
+		return err
+	}
+	o := &output{r: agg, agg: agg}
+	err = errors.Wrap(watchAPIs(ctx, cl.apis, newDeletionWatcher(cl.f, o), cl.dyn, cl.ns, opts.tweakListOptions, opts.timeout), "failed to watch the managed resources")
+	if fErr := agg.flush(); fErr != nil && err == nil {
+		err = errors.Wrap(fErr, "failed to write the time-to-deletion statistics")
+	}
+	return err
+}
+
+// managedAPI is an API serving managed resources.
+type managedAPI struct {
+	gvr        schema.GroupVersionResource
+	gvk        schema.GroupVersionKind
+	namespaced bool
+}
+
+// namespace returns the namespace to list or watch the resources of the API
+// in, given the selected namespace.
+func (a managedAPI) namespace(ns string) string {
+	if !a.namespaced {
+		return metav1.NamespaceAll
+	}
+	return ns
+}
+
+// discoverAPIs discovers the APIs in the specified category matching
+// the specified filters. Discovery may fail for some of the API groups,
+// e.g., for an unavailable aggregated API, in which case a warning is
+// printed and the APIs of the other groups are returned.
+func discoverAPIs(dc discovery.ServerResourcesInterface, f filters, category string) ([]managedAPI, error) {
+	_, rlList, err := dc.ServerGroupsAndResources()
+	var gdErr *discovery.ErrGroupDiscoveryFailed
+	switch {
+	case errors.As(err, &gdErr):
+		warnDiscoveryFailures(gdErr)
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to discover the API resource list")
+	}
+	apis, err := managedAPIs(rlList, f, category)
+	return apis, errors.Wrap(err, "failed to collect the managed resource APIs")
+}
+
+// managedAPIs returns the cluster-scoped and namespaced APIs in the specified
+// category, e.g., managed, matching the specified filters.
+func managedAPIs(rlList []*metav1.APIResourceList, f filters, category string) ([]managedAPI, error) {
+	var apis []managedAPI
 	for _, rl := range rlList {
 		for _, r := range rl.APIResources {
-			if r.Namespaced {
-				continue
-			}
 			managed := false
 			for _, c := range r.Categories {
-				if c == "managed" {
+				if c == category {
 					managed = true
 					break
 				}
@@ -106,7 +421,7 @@ func reportOnAPIs(rlList []*metav1.APIResourceList, f filters, dyn dynamic.Inter
 
 			gv, err := schema.ParseGroupVersion(rl.GroupVersion)
 			if err != nil {
-				return errors.Wrapf(err, "failed to parse GroupVersion string: %s", rl.GroupVersion)
+				return nil, errors.Wrapf(err, "failed to parse GroupVersion string: %s", rl.GroupVersion)
 			}
 			gvr := schema.GroupVersionResource{
 				Group:    gv.Group,
@@ -118,40 +433,20 @@ func reportOnAPIs(rlList []*metav1.APIResourceList, f filters, dyn dynamic.Inter
 				Version: gv.Version,
 				Kind:    r.Kind,
 			}
-			if !f.match(gvk, "") {
+			if !f.match(gvk, "", "", nil) {
 				continue
 			}
-
-			ri := dyn.Resource(gvr)
-			ul, err := ri.List(context.TODO(), metav1.ListOptions{})
-			if err != nil {
-				return errors.Wrapf(err, "failed to list resources with GVR: %s", gvr.String())
-			}
-			for _, u := range ul.Items {
-				if !f.match(gvk, u.GetName()) {
-					continue
-				}
-				reportTTR(gvk, u)
-			}
+			apis = append(apis, managedAPI{gvr: gvr, gvk: gvk, namespaced: r.Namespaced})
 		}
 	}
-	return nil
-}
-
-func reportTTR(gvk schema.GroupVersionKind, u unstructured.Unstructured) {
-	rc := getReadyCondition(u)
-	// resource not ready yet
-	if rc.Status != corev1.ConditionTrue {
-		return
-	}
-	fmt.Printf("%s/%s/%s/%s:%.0f\n", gvk.Group, gvk.Version, gvk.Kind, u.GetName(), rc.LastTransitionTime.Sub(u.GetCreationTimestamp().Time).Seconds())
+	return apis, nil
 }
 
-func getReadyCondition(u unstructured.Unstructured) xpv1.Condition {
+func getCondition(u unstructured.Unstructured, ct xpv1.ConditionType) xpv1.Condition {
 	conditioned := xpv1.ConditionedStatus{}
 	// The path is directly `status` because conditions are inline.
 	if err := fieldpath.Pave(u.Object).GetValueInto("status", &conditioned); err != nil {
 		return xpv1.Condition{}
 	}
-	return conditioned.GetCondition(xpv1.TypeReady)
+	return conditioned.GetCondition(ct)
 }
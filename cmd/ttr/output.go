@@ -0,0 +1,473 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+	outputCSV  = "csv"
+	outputProm = "prom"
+)
+
+// event is the lifecycle event of a managed resource a measurement is
+// reported at.
+type event string
+
+// Lifecycle events
+const (
+	// eventReady is the resource becoming ready.
+	eventReady event = "ready"
+	// eventDeleted is the removal of a deleted resource.
+	eventDeleted event = "deleted"
+	// eventUnready is the resource being found not ready.
+	eventUnready event = "unready"
+)
+
+// measurement is the time-to-readiness measurement of a managed resource,
+// along with the durations of its other lifecycle phases.
+type measurement struct {
+	Event        event     `json:"event"`
+	Group        string    `json:"group"`
+	Version      string    `json:"version"`
+	Kind         string    `json:"kind"`
+	Namespace    string    `json:"namespace,omitempty"`
+	Name         string    `json:"name"`
+	CreationTime time.Time `json:"creationTime"`
+	// ReadyTime is the last transition time of the Ready condition. Nil if
+	// the resource is not ready, which is possible for a deleted resource.
+	ReadyTime *time.Time `json:"readyTime,omitempty"`
+	// TTRSeconds is the time-to-readiness in seconds. Nil if the resource
+	// is not ready.
+	TTRSeconds *float64 `json:"ttrSeconds,omitempty"`
+	// DeletionTime is the deletion timestamp of a deleted resource.
+	DeletionTime *time.Time `json:"deletionTime,omitempty"`
+	// Phases are the durations of the lifecycle phases in seconds.
+	Phases map[phase]float64 `json:"phases,omitempty"`
+	// Synced is the status of the Synced condition.
+	Synced corev1.ConditionStatus `json:"synced,omitempty"`
+	// ProviderPod is the name of the pod of the provider reconciling the
+	// resource, if it could be determined.
+	ProviderPod string `json:"providerPod,omitempty"`
+	// Unready are the details of a resource that is not ready.
+	Unready *unreadyDetails `json:"unready,omitempty"`
+}
+
+// newMeasurement returns the measurement of the specified resource at the
+// specified event. The readiness and the lifecycle phases are computed
+// from the conditions of the resource.
+func newMeasurement(e event, api managedAPI, u *unstructured.Unstructured) measurement {
+	created := u.GetCreationTimestamp().Time
+	m := measurement{
+		Event:        e,
+		Group:        api.gvk.Group,
+		Version:      api.gvk.Version,
+		Kind:         api.gvk.Kind,
+		Namespace:    u.GetNamespace(),
+		Name:         u.GetName(),
+		CreationTime: created,
+		Phases:       conditionPhases(*u),
+		Synced:       getCondition(*u, xpv1.TypeSynced).Status,
+	}
+	if rc := getCondition(*u, xpv1.TypeReady); rc.Status == corev1.ConditionTrue {
+		t := rc.LastTransitionTime.Time
+		ttr := t.Sub(created).Seconds()
+		m.ReadyTime, m.TTRSeconds = &t, &ttr
+	}
+	return m
+}
+
+// resourceString returns the group/version/kind/[namespace/]name
+// representation of a resource.
+func resourceString(gvk schema.GroupVersionKind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Version, gvk.Kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", gvk.Group, gvk.Version, gvk.Kind, namespace, name)
+}
+
+// reporter writes the measurements in an output format.
+type reporter interface {
+	// add reports the specified measurement. Depending on the format,
+	// the measurement is either written immediately or buffered.
+	add(m measurement) error
+	// flush writes the buffered measurements, if any.
+	flush() error
+}
+
+// newReporter returns a reporter writing in the specified format.
+func newReporter(format string, w io.Writer) (reporter, error) {
+	switch format {
+	case outputText:
+		return &textReporter{w: w}, nil
+	case outputJSON, outputYAML:
+		return &structuredReporter{w: w, yaml: format == outputYAML}, nil
+	case outputCSV:
+		return &csvReporter{w: csv.NewWriter(w)}, nil
+	case outputProm:
+		return &promReporter{w: w}, nil
+	default:
+		return nil, errors.Errorf("unknown output format %q: must be one of text, json, yaml, csv, prom", format)
+	}
+}
+
+// teeReporter reports the measurements with multiple reporters.
+type teeReporter []reporter
+
+func (t teeReporter) add(m measurement) error {
+	for _, r := range t {
+		if err := r.add(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeReporter) flush() error {
+	for _, r := range t {
+		if err := r.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textReporter writes a line per ready resource in the
+// group/version/kind/[namespace/]name:seconds format, and a line per
+// not-ready resource with its details. The lifecycle phases are not
+// reported.
+type textReporter struct {
+	w io.Writer
+}
+
+func (r *textReporter) add(m measurement) error {
+	gvk := schema.GroupVersionKind{Group: m.Group, Version: m.Version, Kind: m.Kind}
+	var err error
+	switch m.Event { //nolint:exhaustive // the deletions are not reported in the text format
+	case eventReady:
+		_, err = fmt.Fprintf(r.w, "%s:%.0f\n", resourceString(gvk, m.Namespace, m.Name), *m.TTRSeconds)
+	case eventUnready:
+		_, err = fmt.Fprintf(r.w, "%s:not ready (%s)\n", resourceString(gvk, m.Namespace, m.Name), m.Unready)
+	}
+	return errors.Wrap(err, "failed to write the measurement")
+}
+
+func (r *textReporter) flush() error {
+	return nil
+}
+
+// structuredReporter writes a JSON or YAML list of all the measurements
+// when flushed.
+type structuredReporter struct {
+	w            io.Writer
+	yaml         bool
+	measurements []measurement
+}
+
+func (r *structuredReporter) add(m measurement) error {
+	r.measurements = append(r.measurements, m)
+	return nil
+}
+
+func (r *structuredReporter) flush() error {
+	ms := r.measurements
+	if ms == nil {
+		ms = []measurement{}
+	}
+	var buff []byte
+	var err error
+	if r.yaml {
+		buff, err = yaml.Marshal(ms)
+	} else {
+		buff, err = json.MarshalIndent(ms, "", "  ")
+		buff = append(buff, '\n')
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the measurements")
+	}
+	_, err = r.w.Write(buff)
+	return errors.Wrap(err, "failed to write the measurements")
+}
+
+// csvReporter writes a header followed by a record per measurement, with
+// a column per lifecycle phase.
+type csvReporter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (r *csvReporter) add(m measurement) error {
+	if !r.headerWritten {
+		header := []string{"event", "group", "version", "kind", "namespace", "name", "creationTime", "readyTime", "ttrSeconds", "deletionTime"}
+		for _, p := range phases {
+			header = append(header, string(p)+"Seconds")
+		}
+		header = append(header, "synced", "providerPod", "ageSeconds", "readyReason", "readyMessage", "syncedReason", "syncedMessage", "lastEventReason")
+		if err := r.w.Write(header); err != nil {
+			return errors.Wrap(err, "failed to write the CSV header")
+		}
+		r.headerWritten = true
+	}
+	record := []string{string(m.Event), m.Group, m.Version, m.Kind, m.Namespace, m.Name,
+		m.CreationTime.UTC().Format(time.RFC3339), formatTime(m.ReadyTime), formatSeconds(m.TTRSeconds), formatTime(m.DeletionTime)}
+	for _, p := range phases {
+		var s *float64
+		if v, ok := m.Phases[p]; ok {
+			s = &v
+		}
+		record = append(record, formatSeconds(s))
+	}
+	record = append(record, string(m.Synced), m.ProviderPod)
+	if d := m.Unready; d != nil {
+		record = append(record, formatSeconds(&d.AgeSeconds), string(d.ReadyReason), d.ReadyMessage, string(d.SyncedReason), d.SyncedMessage, d.LastEventReason)
+	} else {
+		record = append(record, "", "", "", "", "", "")
+	}
+	if err := r.w.Write(record); err != nil {
+		return errors.Wrap(err, "failed to write the CSV record")
+	}
+	// flush each record so that the measurements are streamed in
+	// the watch mode.
+	r.w.Flush()
+	return errors.Wrap(r.w.Error(), "failed to write the CSV record")
+}
+
+func (r *csvReporter) flush() error {
+	r.w.Flush()
+	return errors.Wrap(r.w.Error(), "failed to flush the CSV records")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatSeconds(s *float64) string {
+	if s == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*s, 'f', 0, 64)
+}
+
+// promMetricTTR is the name of the Prometheus gauge for the
+// time-to-readiness measurements.
+const promMetricTTR = "uptest_managed_resource_ttr_seconds"
+
+// promMetricUnready is the name of the Prometheus gauge for the ages of
+// the not-ready resources.
+const promMetricUnready = "uptest_managed_resource_unready_age_seconds"
+
+// promMetricPhases are the names and the descriptions of the Prometheus
+// gauges for the lifecycle phases.
+var promMetricPhases = map[phase][2]string{
+	phaseCreationToSynced:             {"uptest_managed_resource_creation_to_synced_seconds", "Duration from the creation of the managed resource until it became synced in seconds."},
+	phaseSyncedToReady:                {"uptest_managed_resource_synced_to_ready_seconds", "Duration from the managed resource becoming synced until it became ready in seconds."},
+	phaseCreationToLastAsyncOperation: {"uptest_managed_resource_creation_to_last_async_operation_seconds", "Duration from the creation of the managed resource until its last asynchronous operation succeeded in seconds."},
+	phaseDeletionToFinalizerRemoval:   {"uptest_managed_resource_deletion_to_finalizer_removal_seconds", "Duration from the deletion of the managed resource until its finalizers were removed in seconds."},
+}
+
+// promReporter writes the measurements in the Prometheus text exposition
+// format, which can be consumed by the node exporter's textfile collector.
+// The measurements are buffered until flushed because the samples of
+// a metric must be grouped together.
+type promReporter struct {
+	w            io.Writer
+	measurements []measurement
+}
+
+func (r *promReporter) add(m measurement) error {
+	r.measurements = append(r.measurements, m)
+	return nil
+}
+
+// flush writes the metrics. A deleted resource has already been reported
+// when it became ready, so only its deletion phase is reported. A series
+// is written only once, with its last sample, because the textfile
+// collector rejects the whole file if a series is duplicated.
+func (r *promReporter) flush() error {
+	var ttr, unready promSamples
+	samples := make(map[phase]*promSamples, len(phases))
+	for _, p := range phases {
+		samples[p] = &promSamples{}
+	}
+	for _, m := range r.measurements {
+		labels := strings.Join([]string{
+			promLabel("group", m.Group),
+			promLabel("version", m.Version),
+			promLabel("kind", m.Kind),
+			promLabel("namespace", m.Namespace),
+			promLabel("name", m.Name),
+			promLabel("synced", string(m.Synced)),
+			promLabel("provider_pod", m.ProviderPod),
+		}, ",")
+		if m.Event == eventDeleted {
+			samples[phaseDeletionToFinalizerRemoval].add(promMetricPhases[phaseDeletionToFinalizerRemoval][0], labels, m.Phases[phaseDeletionToFinalizerRemoval])
+			continue
+		}
+		switch m.Event { //nolint:exhaustive // the deletions are handled above
+		case eventReady:
+			ttr.add(promMetricTTR, labels, *m.TTRSeconds)
+		case eventUnready:
+			reasons := strings.Join([]string{
+				promLabel("ready_reason", string(m.Unready.ReadyReason)),
+				promLabel("synced_reason", string(m.Unready.SyncedReason)),
+				promLabel("last_event_reason", m.Unready.LastEventReason),
+			}, ",")
+			unready.add(promMetricUnready, labels+","+reasons, m.Unready.AgeSeconds)
+		}
+		for p, v := range m.Phases {
+			if p != phaseDeletionToFinalizerRemoval {
+				samples[p].add(promMetricPhases[p][0], labels, v)
+			}
+		}
+	}
+	if err := writePromMetric(r.w, promMetricTTR, "Time-to-readiness of the managed resource in seconds.", ttr.lines); err != nil {
+		return err
+	}
+	if err := writePromMetric(r.w, promMetricUnready, "Age of the managed resource that is not ready in seconds.", unready.lines); err != nil {
+		return err
+	}
+	for _, p := range phases {
+		if err := writePromMetric(r.w, promMetricPhases[p][0], promMetricPhases[p][1], samples[p].lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promSamples are the samples of a metric, which are deduplicated by their
+// label sets in favor of the last sample.
+type promSamples struct {
+	index map[string]int
+	lines []string
+}
+
+func (s *promSamples) add(name, labels string, v float64) {
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+	l := promSample(name, labels, v)
+	if i, ok := s.index[labels]; ok {
+		s.lines[i] = l
+		return
+	}
+	s.index[labels] = len(s.lines)
+	s.lines = append(s.lines, l)
+}
+
+func writePromMetric(w io.Writer, name, help string, samples []string) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s", name, help, name, strings.Join(samples, ""))
+	return errors.Wrapf(err, "failed to write the metric %s", name)
+}
+
+func promSample(name, labels string, v float64) string {
+	return fmt.Sprintf("%s{%s} %s\n", name, labels, strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+var promLabelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promLabel(name, value string) string {
+	return name + `="` + promLabelValueEscaper.Replace(value) + `"`
+}
+
+// output reports the measurements of the ready managed resources. It's
+// safe for concurrent use.
+type output struct {
+	// mu serializes the writes of the reporter.
+	mu sync.Mutex
+	r  reporter
+	// pods resolves the provider pods of the measured resources. Nil if
+	// the output format does not include the provider pods.
+	pods *providerPods
+	// agg is the reporter if the statistics are aggregated.
+	agg *aggregator
+}
+
+// record reports the specified resource if it's ready, and returns
+// whether it's ready.
+func (o *output) record(ctx context.Context, api managedAPI, u *unstructured.Unstructured) (bool, error) {
+	m := newMeasurement(eventReady, api, u)
+	if m.TTRSeconds == nil {
+		return false, nil
+	}
+	return true, o.add(ctx, api, m)
+}
+
+// recordUnready reports the specified resource, which is not ready, with
+// the specified reason of its last event at the specified time.
+func (o *output) recordUnready(ctx context.Context, api managedAPI, u *unstructured.Unstructured, lastEventReason string, now time.Time) error {
+	m := newMeasurement(eventUnready, api, u)
+	m.Unready = newUnreadyDetails(*u, lastEventReason, now)
+	return o.add(ctx, api, m)
+}
+
+// recordDeletion reports the specified deleted resource, whose removal
+// has been observed at the specified time. Resources deleted without
+// finalizers are not reported.
+func (o *output) recordDeletion(ctx context.Context, api managedAPI, u *unstructured.Unstructured, removed time.Time) error {
+	d, ok := deletionPhase(*u, removed)
+	if !ok {
+		return nil
+	}
+	m := newMeasurement(eventDeleted, api, u)
+	m.DeletionTime = &u.GetDeletionTimestamp().Time
+	if m.Phases == nil {
+		m.Phases = make(map[phase]float64, 1)
+	}
+	m.Phases[phaseDeletionToFinalizerRemoval] = d
+	return o.add(ctx, api, m)
+}
+
+func (o *output) add(ctx context.Context, api managedAPI, m measurement) error {
+	if o.pods != nil {
+		m.ProviderPod = o.pods.get(ctx, api)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.r.add(m)
+}
+
+// pending records a resource of the specified GVK that has not reached
+// the aggregated event, e.g., that is not ready.
+func (o *output) pending(gvk schema.GroupVersionKind) {
+	if o.agg != nil {
+		o.agg.addPending(gvk)
+	}
+}
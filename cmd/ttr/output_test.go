@@ -0,0 +1,270 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func readyMeasurement(name string, ttr float64) measurement {
+	ready := testCreated.Add(time.Duration(ttr) * time.Second)
+	return measurement{
+		Event:        eventReady,
+		Group:        "ec2.aws.upbound.io",
+		Version:      "v1beta1",
+		Kind:         "VPC",
+		Name:         name,
+		CreationTime: testCreated,
+		ReadyTime:    &ready,
+		TTRSeconds:   &ttr,
+		Phases:       map[phase]float64{phaseCreationToSynced: 10, phaseSyncedToReady: ttr - 10},
+		Synced:       corev1.ConditionTrue,
+	}
+}
+
+func deletedMeasurement(name string, ttr, d float64) measurement {
+	m := readyMeasurement(name, ttr)
+	m.Event = eventDeleted
+	m.DeletionTime = &testDeleted
+	m.Phases[phaseDeletionToFinalizerRemoval] = d
+	return m
+}
+
+func TestPromReporter(t *testing.T) {
+	cases := map[string]struct {
+		reason       string
+		measurements []measurement
+		want         string
+	}{
+		"ReadyAndDeleted": {
+			reason:       "Only the deletion phase of a deleted resource should be reported, so that the phases reported when it became ready are not duplicated.",
+			measurements: []measurement{readyMeasurement("vpc", 30), deletedMeasurement("vpc", 30, 5)},
+			want: `# HELP uptest_managed_resource_ttr_seconds Time-to-readiness of the managed resource in seconds.
+# TYPE uptest_managed_resource_ttr_seconds gauge
+uptest_managed_resource_ttr_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 30
+# HELP uptest_managed_resource_creation_to_synced_seconds Duration from the creation of the managed resource until it became synced in seconds.
+# TYPE uptest_managed_resource_creation_to_synced_seconds gauge
+uptest_managed_resource_creation_to_synced_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 10
+# HELP uptest_managed_resource_synced_to_ready_seconds Duration from the managed resource becoming synced until it became ready in seconds.
+# TYPE uptest_managed_resource_synced_to_ready_seconds gauge
+uptest_managed_resource_synced_to_ready_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 20
+# HELP uptest_managed_resource_deletion_to_finalizer_removal_seconds Duration from the deletion of the managed resource until its finalizers were removed in seconds.
+# TYPE uptest_managed_resource_deletion_to_finalizer_removal_seconds gauge
+uptest_managed_resource_deletion_to_finalizer_removal_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 5
+`,
+		},
+		"DuplicateSeries": {
+			reason:       "A resource reported more than once should have a single sample per series, with its last value.",
+			measurements: []measurement{readyMeasurement("vpc", 30), readyMeasurement("vpc", 40)},
+			want: `# HELP uptest_managed_resource_ttr_seconds Time-to-readiness of the managed resource in seconds.
+# TYPE uptest_managed_resource_ttr_seconds gauge
+uptest_managed_resource_ttr_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 40
+# HELP uptest_managed_resource_creation_to_synced_seconds Duration from the creation of the managed resource until it became synced in seconds.
+# TYPE uptest_managed_resource_creation_to_synced_seconds gauge
+uptest_managed_resource_creation_to_synced_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 10
+# HELP uptest_managed_resource_synced_to_ready_seconds Duration from the managed resource becoming synced until it became ready in seconds.
+# TYPE uptest_managed_resource_synced_to_ready_seconds gauge
+uptest_managed_resource_synced_to_ready_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc",synced="True",provider_pod=""} 30
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buff := &bytes.Buffer{}
+			r := &promReporter{w: buff}
+			for _, m := range tc.measurements {
+				if err := r.add(m); err != nil {
+					t.Fatalf("add(...): unexpected error: %v", err)
+				}
+			}
+			if err := r.flush(); err != nil {
+				t.Fatalf("flush(): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, buff.String()); diff != "" {
+				t.Errorf("\n%s\nflush(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReporters(t *testing.T) {
+	subnet := readyMeasurement("subnet", 60)
+	subnet.Kind = "Subnet"
+	subnet.ProviderPod = "provider-aws-ec2-abc-1"
+	unready := measurement{
+		Event:        eventUnready,
+		Group:        "ec2.m.aws.upbound.io",
+		Version:      "v1beta1",
+		Kind:         "Subnet",
+		Namespace:    "test",
+		Name:         "subnet",
+		CreationTime: testCreated,
+		Synced:       corev1.ConditionFalse,
+		Unready: &unreadyDetails{
+			AgeSeconds:      120,
+			SyncedReason:    "ReconcileError",
+			SyncedMessage:   "cannot create, the VPC is not ready",
+			LastEventReason: "CannotCreateExternalResource",
+		},
+	}
+	cases := map[string]struct {
+		reason       string
+		format       string
+		measurements []measurement
+		want         string
+	}{
+		"Text": {
+			reason:       "A line should be written per ready or not-ready resource, and the deletions should not be reported.",
+			format:       outputText,
+			measurements: []measurement{readyMeasurement("vpc", 30), subnet, unready, deletedMeasurement("vpc", 30, 5)},
+			want: `ec2.aws.upbound.io/v1beta1/VPC/vpc:30
+ec2.aws.upbound.io/v1beta1/Subnet/subnet:60
+ec2.m.aws.upbound.io/v1beta1/Subnet/test/subnet:not ready (age: 2m0s, Synced: ReconcileError: cannot create, the VPC is not ready, last event: CannotCreateExternalResource)
+`,
+		},
+		"JSON": {
+			reason:       "A JSON list of the measurements should be written with their phases and details.",
+			format:       outputJSON,
+			measurements: []measurement{readyMeasurement("vpc", 30), subnet, unready},
+			want: `[
+  {
+    "event": "ready",
+    "group": "ec2.aws.upbound.io",
+    "version": "v1beta1",
+    "kind": "VPC",
+    "name": "vpc",
+    "creationTime": "2026-01-01T00:00:00Z",
+    "readyTime": "2026-01-01T00:00:30Z",
+    "ttrSeconds": 30,
+    "phases": {
+      "creationToSynced": 10,
+      "syncedToReady": 20
+    },
+    "synced": "True"
+  },
+  {
+    "event": "ready",
+    "group": "ec2.aws.upbound.io",
+    "version": "v1beta1",
+    "kind": "Subnet",
+    "name": "subnet",
+    "creationTime": "2026-01-01T00:00:00Z",
+    "readyTime": "2026-01-01T00:01:00Z",
+    "ttrSeconds": 60,
+    "phases": {
+      "creationToSynced": 10,
+      "syncedToReady": 50
+    },
+    "synced": "True",
+    "providerPod": "provider-aws-ec2-abc-1"
+  },
+  {
+    "event": "unready",
+    "group": "ec2.m.aws.upbound.io",
+    "version": "v1beta1",
+    "kind": "Subnet",
+    "namespace": "test",
+    "name": "subnet",
+    "creationTime": "2026-01-01T00:00:00Z",
+    "synced": "False",
+    "unready": {
+      "ageSeconds": 120,
+      "syncedReason": "ReconcileError",
+      "syncedMessage": "cannot create, the VPC is not ready",
+      "lastEventReason": "CannotCreateExternalResource"
+    }
+  }
+]
+`,
+		},
+		"EmptyJSON": {
+			reason: "An empty JSON list should be written without any measurements.",
+			format: outputJSON,
+			want:   "[]\n",
+		},
+		"YAML": {
+			reason:       "A YAML list of the measurements should be written.",
+			format:       outputYAML,
+			measurements: []measurement{readyMeasurement("vpc", 30)},
+			want: `- creationTime: "2026-01-01T00:00:00Z"
+  event: ready
+  group: ec2.aws.upbound.io
+  kind: VPC
+  name: vpc
+  phases:
+    creationToSynced: 10
+    syncedToReady: 20
+  readyTime: "2026-01-01T00:00:30Z"
+  synced: "True"
+  ttrSeconds: 30
+  version: v1beta1
+`,
+		},
+		"CSV": {
+			reason:       "A header and a record per measurement should be written, with a column per lifecycle phase.",
+			format:       outputCSV,
+			measurements: []measurement{readyMeasurement("vpc", 30), subnet, unready, deletedMeasurement("vpc", 30, 5)},
+			want: `event,group,version,kind,namespace,name,creationTime,readyTime,ttrSeconds,deletionTime,creationToSyncedSeconds,syncedToReadySeconds,creationToLastAsyncOperationSeconds,deletionToFinalizerRemovalSeconds,synced,providerPod,ageSeconds,readyReason,readyMessage,syncedReason,syncedMessage,lastEventReason
+ready,ec2.aws.upbound.io,v1beta1,VPC,,vpc,2026-01-01T00:00:00Z,2026-01-01T00:00:30Z,30,,10,20,,,True,,,,,,,
+ready,ec2.aws.upbound.io,v1beta1,Subnet,,subnet,2026-01-01T00:00:00Z,2026-01-01T00:01:00Z,60,,10,50,,,True,provider-aws-ec2-abc-1,,,,,,
+unready,ec2.m.aws.upbound.io,v1beta1,Subnet,test,subnet,2026-01-01T00:00:00Z,,,,,,,,False,,120,,,ReconcileError,"cannot create, the VPC is not ready",CannotCreateExternalResource
+deleted,ec2.aws.upbound.io,v1beta1,VPC,,vpc,2026-01-01T00:00:00Z,2026-01-01T00:00:30Z,30,2026-01-01T01:00:00Z,10,20,,5,True,,,,,,,
+`,
+		},
+		"Prometheus": {
+			reason:       "The samples of each metric should be grouped together with the escaped label values.",
+			format:       outputProm,
+			measurements: []measurement{readyMeasurement(`vpc"1`, 30), subnet},
+			want: `# HELP uptest_managed_resource_ttr_seconds Time-to-readiness of the managed resource in seconds.
+# TYPE uptest_managed_resource_ttr_seconds gauge
+uptest_managed_resource_ttr_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc\"1",synced="True",provider_pod=""} 30
+uptest_managed_resource_ttr_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="Subnet",namespace="",name="subnet",synced="True",provider_pod="provider-aws-ec2-abc-1"} 60
+# HELP uptest_managed_resource_creation_to_synced_seconds Duration from the creation of the managed resource until it became synced in seconds.
+# TYPE uptest_managed_resource_creation_to_synced_seconds gauge
+uptest_managed_resource_creation_to_synced_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc\"1",synced="True",provider_pod=""} 10
+uptest_managed_resource_creation_to_synced_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="Subnet",namespace="",name="subnet",synced="True",provider_pod="provider-aws-ec2-abc-1"} 10
+# HELP uptest_managed_resource_synced_to_ready_seconds Duration from the managed resource becoming synced until it became ready in seconds.
+# TYPE uptest_managed_resource_synced_to_ready_seconds gauge
+uptest_managed_resource_synced_to_ready_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="VPC",namespace="",name="vpc\"1",synced="True",provider_pod=""} 20
+uptest_managed_resource_synced_to_ready_seconds{group="ec2.aws.upbound.io",version="v1beta1",kind="Subnet",namespace="",name="subnet",synced="True",provider_pod="provider-aws-ec2-abc-1"} 50
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buff := &bytes.Buffer{}
+			r, err := newReporter(tc.format, buff)
+			if err != nil {
+				t.Fatalf("newReporter(...): unexpected error: %v", err)
+			}
+			for _, m := range tc.measurements {
+				if err := r.add(m); err != nil {
+					t.Fatalf("add(...): unexpected error: %v", err)
+				}
+			}
+			if err := r.flush(); err != nil {
+				t.Fatalf("flush(): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, buff.String()); diff != "" {
+				t.Errorf("\n%s\nflush(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
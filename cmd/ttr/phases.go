@@ -0,0 +1,86 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// typeLastAsyncOperation is the type of the condition reporting the result
+// of the last asynchronous operation of the upjet-based providers.
+const typeLastAsyncOperation xpv1.ConditionType = "LastAsyncOperation"
+
+// phase is a lifecycle phase of a managed resource.
+type phase string
+
+// Lifecycle phases
+const (
+	// phaseCreationToSynced is from the creation of the resource until its
+	// Synced condition became true.
+	phaseCreationToSynced phase = "creationToSynced"
+	// phaseSyncedToReady is from the Synced condition becoming true until
+	// the Ready condition became true.
+	phaseSyncedToReady phase = "syncedToReady"
+	// phaseCreationToLastAsyncOperation is from the creation of the resource
+	// until its last asynchronous operation succeeded.
+	phaseCreationToLastAsyncOperation phase = "creationToLastAsyncOperation"
+	// phaseDeletionToFinalizerRemoval is from the deletion of the resource
+	// until its finalizers were removed and it was gone.
+	phaseDeletionToFinalizerRemoval phase = "deletionToFinalizerRemoval"
+)
+
+// phases are the lifecycle phases in their reporting order.
+var phases = []phase{phaseCreationToSynced, phaseSyncedToReady, phaseCreationToLastAsyncOperation, phaseDeletionToFinalizerRemoval}
+
+// conditionPhases returns the durations in seconds of the lifecycle phases
+// that can be computed from the conditions of the specified resource.
+// The conditions only record their last transitions, so a phase is omitted
+// if its condition is not true or it transitioned out of order, e.g., if
+// the resource got out of sync after becoming ready.
+func conditionPhases(u unstructured.Unstructured) map[phase]float64 {
+	created := u.GetCreationTimestamp().Time
+	ps := make(map[phase]float64)
+	sc := getCondition(u, xpv1.TypeSynced)
+	rc := getCondition(u, xpv1.TypeReady)
+	if sc.Status == corev1.ConditionTrue {
+		ps[phaseCreationToSynced] = sc.LastTransitionTime.Sub(created).Seconds()
+		if rc.Status == corev1.ConditionTrue && !rc.LastTransitionTime.Before(&sc.LastTransitionTime) {
+			ps[phaseSyncedToReady] = rc.LastTransitionTime.Sub(sc.LastTransitionTime.Time).Seconds()
+		}
+	}
+	if ac := getCondition(u, typeLastAsyncOperation); ac.Status == corev1.ConditionTrue {
+		ps[phaseCreationToLastAsyncOperation] = ac.LastTransitionTime.Sub(created).Seconds()
+	}
+	if len(ps) == 0 {
+		return nil
+	}
+	return ps
+}
+
+// deletionPhase returns the duration in seconds from the deletion of
+// the specified resource until the specified time its removal has been
+// observed at, and whether the resource had been deleted gracefully, i.e.,
+// its removal was blocked by finalizers.
+func deletionPhase(u unstructured.Unstructured, removed time.Time) (float64, bool) {
+	dt := u.GetDeletionTimestamp()
+	if dt == nil {
+		return 0, false
+	}
+	return removed.Sub(dt.Time).Seconds(), true
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConditionPhases(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		conditions []xpv1.Condition
+		want       map[phase]float64
+	}{
+		"SyncedAndReady": {
+			reason: "The durations until the resource became synced and then ready should be computed.",
+			conditions: []xpv1.Condition{
+				condition(xpv1.TypeSynced, corev1.ConditionTrue, 10),
+				condition(xpv1.TypeReady, corev1.ConditionTrue, 30),
+			},
+			want: map[phase]float64{phaseCreationToSynced: 10, phaseSyncedToReady: 20},
+		},
+		"SyncedNotReady": {
+			reason: "Only the duration until the resource became synced should be computed if it is not ready.",
+			conditions: []xpv1.Condition{
+				condition(xpv1.TypeSynced, corev1.ConditionTrue, 10),
+				condition(xpv1.TypeReady, corev1.ConditionFalse, 30),
+			},
+			want: map[phase]float64{phaseCreationToSynced: 10},
+		},
+		"ReadyBeforeSynced": {
+			reason: "The synced-to-ready phase should be omitted if the resource became synced again after becoming ready.",
+			conditions: []xpv1.Condition{
+				condition(xpv1.TypeSynced, corev1.ConditionTrue, 60),
+				condition(xpv1.TypeReady, corev1.ConditionTrue, 30),
+			},
+			want: map[phase]float64{phaseCreationToSynced: 60},
+		},
+		"NotSynced": {
+			reason: "The Ready condition should not be used if the resource is not synced.",
+			conditions: []xpv1.Condition{
+				condition(xpv1.TypeSynced, corev1.ConditionFalse, 10),
+				condition(xpv1.TypeReady, corev1.ConditionTrue, 30),
+			},
+		},
+		"LastAsyncOperation": {
+			reason: "The duration until the last asynchronous operation succeeded should be computed.",
+			conditions: []xpv1.Condition{
+				condition(xpv1.TypeSynced, corev1.ConditionTrue, 10),
+				condition(xpv1.TypeReady, corev1.ConditionTrue, 30),
+				condition(typeLastAsyncOperation, corev1.ConditionTrue, 25),
+			},
+			want: map[phase]float64{phaseCreationToSynced: 10, phaseSyncedToReady: 20, phaseCreationToLastAsyncOperation: 25},
+		},
+		"NoConditions": {
+			reason: "No phases should be computed for a resource without conditions.",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := conditionPhases(newManaged(t, "vpc", tc.conditions...))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nconditionPhases(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeletionPhase(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		deleted     *time.Time
+		removed     time.Time
+		want        float64
+		wantDeleted bool
+	}{
+		"Graceful": {
+			reason:      "The duration from the deletion until the removal should be computed.",
+			deleted:     &testCreated,
+			removed:     testCreated.Add(45 * time.Second),
+			want:        45,
+			wantDeleted: true,
+		},
+		"NoDeletionTimestamp": {
+			reason:  "A resource removed without a deletion timestamp should not have a deletion phase.",
+			removed: testCreated.Add(45 * time.Second),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := newManaged(t, "vpc")
+			if tc.deleted != nil {
+				dt := metav1.NewTime(*tc.deleted)
+				u.SetDeletionTimestamp(&dt)
+			}
+			got, deleted := deletionPhase(u, tc.removed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ndeletionPhase(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("\n%s\ndeletionPhase(...): got deleted %t, want %t", tc.reason, deleted, tc.wantDeleted)
+			}
+		})
+	}
+}
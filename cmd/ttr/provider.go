@@ -0,0 +1,158 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	kindProviderRevision = "ProviderRevision"
+	labelRevision        = "pkg.crossplane.io/revision"
+	labelPackage         = "pkg.crossplane.io/package"
+)
+
+var (
+	gvrCRD              = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	gvrPod              = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	gvrProviderRevision = schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1", Resource: "providerrevisions"}
+)
+
+// providerResources returns the resources of the CRDs owned by
+// the revisions of the specified provider package, or by the specified
+// provider revision.
+func providerResources(ctx context.Context, dyn dynamic.Interface, provider string) (map[schema.GroupResource]struct{}, error) {
+	prl, err := dyn.Resource(gvrProviderRevision).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the provider revisions")
+	}
+	revisions := make(map[string]struct{})
+	for _, pr := range prl.Items {
+		if pr.GetName() == provider || pr.GetLabels()[labelPackage] == provider {
+			revisions[pr.GetName()] = struct{}{}
+		}
+	}
+	if len(revisions) == 0 {
+		return nil, errors.Errorf("no provider revisions found for the provider %q", provider)
+	}
+	crdl, err := dyn.Resource(gvrCRD).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the CRDs")
+	}
+	owned := make(map[schema.GroupResource]struct{})
+	for _, crd := range crdl.Items {
+		for _, o := range crd.GetOwnerReferences() {
+			if _, ok := revisions[o.Name]; ok && o.Kind == kindProviderRevision {
+				// CRD names are of the form <plural>.<group>
+				owned[schema.ParseGroupResource(crd.GetName())] = struct{}{}
+				break
+			}
+		}
+	}
+	return owned, nil
+}
+
+// ownedAPIs returns the APIs serving the specified resources.
+func ownedAPIs(apis []managedAPI, owned map[schema.GroupResource]struct{}) []managedAPI {
+	result := make([]managedAPI, 0, len(apis))
+	for _, api := range apis {
+		if _, ok := owned[api.gvr.GroupResource()]; ok {
+			result = append(result, api)
+		}
+	}
+	return result
+}
+
+// providerPods resolves the pods of the providers serving the managed
+// resource APIs. A managed resource's CRD is owned by the revision of the
+// provider package that installed it, and the provider pods are labeled
+// with that revision. The resolution is best effort: an empty name is
+// returned if the pod cannot be determined, e.g., due to missing RBAC.
+type providerPods struct {
+	dyn dynamic.Interface
+	// mu guards the cache, but not the resolution of its entries.
+	mu    sync.Mutex
+	cache map[schema.GroupResource]*providerPod
+}
+
+// providerPod is a cache entry for the provider pod serving an API,
+// which is resolved once.
+type providerPod struct {
+	once sync.Once
+	name string
+}
+
+func newProviderPods(dyn dynamic.Interface) *providerPods {
+	return &providerPods{
+		dyn:   dyn,
+		cache: make(map[schema.GroupResource]*providerPod),
+	}
+}
+
+// get returns the name of the provider pod serving the specified API.
+// If there are multiple replicas, the name of the first one in
+// lexicographical order is returned. The pods of different APIs are
+// resolved concurrently, while the concurrent calls for the same API
+// wait for a single resolution.
+func (p *providerPods) get(ctx context.Context, api managedAPI) string {
+	gr := api.gvr.GroupResource()
+	p.mu.Lock()
+	e, ok := p.cache[gr]
+	if !ok {
+		e = &providerPod{}
+		p.cache[gr] = e
+	}
+	p.mu.Unlock()
+	e.once.Do(func() {
+		e.name = p.resolve(ctx, gr)
+	})
+	return e.name
+}
+
+func (p *providerPods) resolve(ctx context.Context, gr schema.GroupResource) string {
+	crd, err := p.dyn.Resource(gvrCRD).Get(ctx, gr.String(), metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	var revision string
+	for _, o := range crd.GetOwnerReferences() {
+		if o.Kind == kindProviderRevision {
+			revision = o.Name
+			break
+		}
+	}
+	if revision == "" {
+		return ""
+	}
+	pods, err := p.dyn.Resource(gvrPod).List(ctx, metav1.ListOptions{
+		LabelSelector: labelRevision + "=" + revision,
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.GetName())
+	}
+	sort.Strings(names)
+	return names[0]
+}
@@ -0,0 +1,332 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var (
+	apiBucket = managedAPI{
+		gvr: schema.GroupVersionResource{Group: "s3.aws.upbound.io", Version: "v1beta1", Resource: "buckets"},
+		gvk: schema.GroupVersionKind{Group: "s3.aws.upbound.io", Version: "v1beta1", Kind: "Bucket"},
+	}
+
+	gvEC2 = schema.GroupVersion{Group: "ec2.aws.upbound.io", Version: "v1beta1"}
+	gvS3  = schema.GroupVersion{Group: "s3.aws.upbound.io", Version: "v1beta1"}
+
+	// testResourceLists are the discovered API resources: the managed
+	// resources of the EC2 and S3 groups, and a provider config, which is
+	// not a managed resource.
+	testResourceLists = []*metav1.APIResourceList{
+		{
+			GroupVersion: gvEC2.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "vpcs", Kind: "VPC", Categories: []string{"crossplane", "managed", "aws"}},
+				{Name: "providerconfigs", Kind: "ProviderConfig", Categories: []string{"crossplane", "providerconfig", "aws"}},
+			},
+		},
+		{
+			GroupVersion: "ec2.m.aws.upbound.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "subnets", Kind: "Subnet", Namespaced: true, Categories: []string{"crossplane", "managed", "aws"}},
+			},
+		},
+		{
+			GroupVersion: gvS3.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "buckets", Kind: "Bucket", Categories: []string{"crossplane", "managed"}},
+			},
+		},
+	}
+)
+
+// fakeDiscovery discovers the configured API resources, failing with
+// the configured error.
+type fakeDiscovery struct {
+	discovery.ServerResourcesInterface
+	rlList []*metav1.APIResourceList
+	err    error
+}
+
+func (d *fakeDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	return nil, d.rlList, d.err
+}
+
+func newFakeDynamicClient(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			gvrCRD:              "CustomResourceDefinitionList",
+			gvrPod:              "PodList",
+			gvrProviderRevision: "ProviderRevisionList",
+			gvrEvent:            "EventList",
+		}, objs...)
+}
+
+func newCRD(name string, revisions ...string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apiextensions.k8s.io/v1")
+	u.SetKind("CustomResourceDefinition")
+	u.SetName(name)
+	refs := make([]metav1.OwnerReference, 0, len(revisions))
+	for _, r := range revisions {
+		refs = append(refs, metav1.OwnerReference{APIVersion: "pkg.crossplane.io/v1", Kind: kindProviderRevision, Name: r})
+	}
+	u.SetOwnerReferences(refs)
+	return u
+}
+
+func newProviderRevision(name, pkg string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("pkg.crossplane.io/v1")
+	u.SetKind(kindProviderRevision)
+	u.SetName(name)
+	u.SetLabels(map[string]string{labelPackage: pkg})
+	return u
+}
+
+func newPod(namespace, name, revision string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("Pod")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(map[string]string{labelRevision: revision})
+	return u
+}
+
+func TestProviderPodsGet(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		objs   []runtime.Object
+		api    managedAPI
+		want   string
+	}{
+		"FirstReplica": {
+			reason: "The first pod of the revision owning the API's CRD should be returned in lexicographical order.",
+			objs: []runtime.Object{
+				newCRD("vpcs.ec2.aws.upbound.io", "provider-aws-ec2-abc"),
+				newPod("crossplane-system", "provider-aws-ec2-abc-2", "provider-aws-ec2-abc"),
+				newPod("crossplane-system", "provider-aws-ec2-abc-1", "provider-aws-ec2-abc"),
+				newPod("crossplane-system", "provider-aws-s3-def-1", "provider-aws-s3-def"),
+			},
+			api:  apiVPC,
+			want: "provider-aws-ec2-abc-1",
+		},
+		"NoCRD": {
+			reason: "An empty name should be returned if the API's CRD cannot be found.",
+			api:    apiVPC,
+		},
+		"NoPods": {
+			reason: "An empty name should be returned if the revision has no pods.",
+			objs:   []runtime.Object{newCRD("vpcs.ec2.aws.upbound.io", "provider-aws-ec2-abc")},
+			api:    apiVPC,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := newFakeDynamicClient(tc.objs...)
+			p := newProviderPods(dyn)
+			// the concurrent calls for the same API should resolve
+			// the pod once and get the same result.
+			var wg sync.WaitGroup
+			got := make([]string, 10)
+			for i := range got {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got[i] = p.get(context.Background(), tc.api)
+				}()
+			}
+			wg.Wait()
+			for _, g := range got {
+				if g != tc.want {
+					t.Errorf("\n%s\nget(...): got %q, want %q", tc.reason, g, tc.want)
+				}
+			}
+			gets := 0
+			for _, a := range dyn.Actions() {
+				if a.GetVerb() == "get" {
+					gets++
+				}
+			}
+			if gets != 1 {
+				t.Errorf("\n%s\nget(...): got %d CRD lookups, want 1", tc.reason, gets)
+			}
+		})
+	}
+}
+
+func TestDiscoverAPIs(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		dc       *fakeDiscovery
+		filters  []string
+		category string
+		want     []managedAPI
+		wantErr  bool
+	}{
+		"ManagedCategory": {
+			reason:   "The cluster-scoped and namespaced APIs in the managed category should be returned.",
+			dc:       &fakeDiscovery{rlList: testResourceLists},
+			category: "managed",
+			want:     []managedAPI{apiVPC, apiSubnet, apiBucket},
+		},
+		"ProviderCategory": {
+			reason:   "Only the APIs in the specified category should be returned.",
+			dc:       &fakeDiscovery{rlList: testResourceLists},
+			category: "aws",
+			want: []managedAPI{apiVPC, {
+				gvr: schema.GroupVersionResource{Group: "ec2.aws.upbound.io", Version: "v1beta1", Resource: "providerconfigs"},
+				gvk: schema.GroupVersionKind{Group: "ec2.aws.upbound.io", Version: "v1beta1", Kind: "ProviderConfig"},
+			}, apiSubnet},
+		},
+		"Filtered": {
+			reason:   "Only the APIs matching the filters should be returned.",
+			dc:       &fakeDiscovery{rlList: testResourceLists},
+			filters:  []string{"s3.aws.upbound.io///"},
+			category: "managed",
+			want:     []managedAPI{apiBucket},
+		},
+		"PartialDiscoveryFailure": {
+			reason: "The APIs of the discovered groups should be returned if discovery fails for some of the groups.",
+			dc: &fakeDiscovery{
+				rlList: testResourceLists[:1],
+				err: &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+					gvS3: errors.New("the server is currently unable to handle the request"),
+				}},
+			},
+			category: "managed",
+			want:     []managedAPI{apiVPC},
+		},
+		"DiscoveryFailure": {
+			reason:   "An error should be returned if discovery fails.",
+			dc:       &fakeDiscovery{err: errors.New("boom")},
+			category: "managed",
+			wantErr:  true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := getFilters(tc.filters...)
+			if err != nil {
+				t.Fatalf("getFilters(...): unexpected error: %v", err)
+			}
+			got, err := discoverAPIs(tc.dc, f, tc.category)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\ndiscoverAPIs(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(managedAPI{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\ndiscoverAPIs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestProviderResources(t *testing.T) {
+	const pkgEC2 = "xpkg.upbound.io/upbound/provider-aws-ec2"
+	objs := []runtime.Object{
+		newProviderRevision("provider-aws-ec2-abc", pkgEC2),
+		newProviderRevision("provider-aws-ec2-def", pkgEC2),
+		newProviderRevision("provider-aws-s3-ghi", "xpkg.upbound.io/upbound/provider-aws-s3"),
+		newCRD("vpcs.ec2.aws.upbound.io", "provider-aws-ec2-abc"),
+		newCRD("subnets.ec2.m.aws.upbound.io", "provider-aws-ec2-def"),
+		newCRD("buckets.s3.aws.upbound.io", "provider-aws-s3-ghi"),
+		newCRD("compositions.apiextensions.crossplane.io"),
+	}
+	cases := map[string]struct {
+		reason   string
+		provider string
+		want     map[schema.GroupResource]struct{}
+		wantErr  bool
+	}{
+		"Package": {
+			reason:   "The resources of the CRDs owned by any revision of the provider package should be returned.",
+			provider: pkgEC2,
+			want: map[schema.GroupResource]struct{}{
+				apiVPC.gvr.GroupResource():    {},
+				apiSubnet.gvr.GroupResource(): {},
+			},
+		},
+		"Revision": {
+			reason:   "Only the resources of the CRDs owned by the specified provider revision should be returned.",
+			provider: "provider-aws-ec2-def",
+			want: map[schema.GroupResource]struct{}{
+				apiSubnet.gvr.GroupResource(): {},
+			},
+		},
+		"UnknownProvider": {
+			reason:   "An error should be returned if the provider has no revisions.",
+			provider: "xpkg.upbound.io/upbound/provider-gcp",
+			wantErr:  true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := providerResources(context.Background(), newFakeDynamicClient(objs...), tc.provider)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nproviderResources(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nproviderResources(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOwnedAPIs(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		apis   []managedAPI
+		owned  map[schema.GroupResource]struct{}
+		want   []managedAPI
+	}{
+		"Owned": {
+			reason: "Only the APIs serving the owned resources should be returned in their order.",
+			apis:   []managedAPI{apiVPC, apiSubnet, apiBucket},
+			owned: map[schema.GroupResource]struct{}{
+				apiBucket.gvr.GroupResource(): {},
+				apiVPC.gvr.GroupResource():    {},
+			},
+			want: []managedAPI{apiVPC, apiBucket},
+		},
+		"NoneOwned": {
+			reason: "No APIs should be returned if none of the resources are owned.",
+			apis:   []managedAPI{apiVPC, apiSubnet},
+			owned:  map[schema.GroupResource]struct{}{},
+			want:   []managedAPI{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ownedAPIs(tc.apis, tc.owned)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(managedAPI{})); diff != "" {
+				t.Errorf("\n%s\nownedAPIs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
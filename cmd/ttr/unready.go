@@ -0,0 +1,191 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+var gvrEvent = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// unreadyDetails are the details of a managed resource that is not ready.
+type unreadyDetails struct {
+	// AgeSeconds is the age of the resource in seconds.
+	AgeSeconds float64 `json:"ageSeconds"`
+	// ReadyReason is the reason of the Ready condition.
+	ReadyReason xpv1.ConditionReason `json:"readyReason,omitempty"`
+	// ReadyMessage is the message of the Ready condition.
+	ReadyMessage string `json:"readyMessage,omitempty"`
+	// SyncedReason is the reason of the Synced condition.
+	SyncedReason xpv1.ConditionReason `json:"syncedReason,omitempty"`
+	// SyncedMessage is the message of the Synced condition.
+	SyncedMessage string `json:"syncedMessage,omitempty"`
+	// LastEventReason is the reason of the last event recorded for
+	// the resource, if any.
+	LastEventReason string `json:"lastEventReason,omitempty"`
+}
+
+// newUnreadyDetails returns the details of the specified resource, which is
+// not ready, at the specified time.
+func newUnreadyDetails(u unstructured.Unstructured, lastEventReason string, now time.Time) *unreadyDetails {
+	rc := getCondition(u, xpv1.TypeReady)
+	sc := getCondition(u, xpv1.TypeSynced)
+	return &unreadyDetails{
+		AgeSeconds:      now.Sub(u.GetCreationTimestamp().Time).Seconds(),
+		ReadyReason:     rc.Reason,
+		ReadyMessage:    rc.Message,
+		SyncedReason:    sc.Reason,
+		SyncedMessage:   sc.Message,
+		LastEventReason: lastEventReason,
+	}
+}
+
+// reasons returns the non-empty reasons of the details, each prefixed with
+// its source, e.g., Synced: ReconcileError.
+func (d *unreadyDetails) reasons() []string {
+	var reasons []string
+	if d.ReadyReason != "" {
+		reasons = append(reasons, "Ready: "+string(d.ReadyReason))
+	}
+	if d.SyncedReason != "" {
+		reasons = append(reasons, "Synced: "+string(d.SyncedReason))
+	}
+	if d.LastEventReason != "" {
+		reasons = append(reasons, "Event: "+d.LastEventReason)
+	}
+	return reasons
+}
+
+// String returns a human-readable representation of the details.
+func (d *unreadyDetails) String() string {
+	parts := []string{"age: " + (time.Duration(d.AgeSeconds) * time.Second).String()}
+	for _, c := range []struct {
+		name            string
+		reason, message string
+	}{
+		{name: "Ready", reason: string(d.ReadyReason), message: d.ReadyMessage},
+		{name: "Synced", reason: string(d.SyncedReason), message: d.SyncedMessage},
+	} {
+		if c.reason == "" {
+			continue
+		}
+		s := c.name + ": " + c.reason
+		if c.message != "" {
+			s += ": " + strings.ReplaceAll(c.message, "\n", " ")
+		}
+		parts = append(parts, s)
+	}
+	if d.LastEventReason != "" {
+		parts = append(parts, "last event: "+d.LastEventReason)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lastEventReasons returns the reasons of the last events recorded for
+// the involved objects, indexed by their UIDs. The events are listed once
+// in pages of the specified size. The events of the cluster-scoped
+// resources are recorded in the default namespace, so the events are
+// listed in all namespaces.
+func lastEventReasons(ctx context.Context, dyn dynamic.Interface, limit int64) (map[types.UID]string, error) {
+	type lastEvent struct {
+		reason string
+		time   time.Time
+	}
+	last := make(map[types.UID]lastEvent)
+	lo := metav1.ListOptions{Limit: limit}
+	for {
+		el, err := dyn.Resource(gvrEvent).List(ctx, lo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list the events")
+		}
+		for _, item := range el.Items {
+			e := &corev1.Event{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, e); err != nil {
+				continue
+			}
+			t := eventTime(e)
+			if l, ok := last[e.InvolvedObject.UID]; !ok || t.After(l.time) {
+				last[e.InvolvedObject.UID] = lastEvent{reason: e.Reason, time: t}
+			}
+		}
+		lo.Continue = el.GetContinue()
+		if lo.Continue == "" {
+			break
+		}
+	}
+	reasons := make(map[types.UID]string, len(last))
+	for uid, l := range last {
+		reasons[uid] = l.reason
+	}
+	return reasons, nil
+}
+
+// eventTime returns the time the specified event was last observed at.
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// renderReasons writes a table of the numbers of the not-ready resources
+// per GVK and reason, sorted by the GVKs and the descending numbers.
+func renderReasons(w io.Writer, stats []apiStatistics) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "GVK\tREASON\tNOT READY"); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		reasons := make([]string, 0, len(s.Reasons))
+		for r := range s.Reasons {
+			reasons = append(reasons, r)
+		}
+		sort.Slice(reasons, func(i, j int) bool {
+			ni, nj := s.Reasons[reasons[i]], s.Reasons[reasons[j]]
+			if ni != nj {
+				return ni > nj
+			}
+			return reasons[i] < reasons[j]
+		})
+		for _, r := range reasons {
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%d\n", s.API, r, s.Reasons[r]); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Flush()
+}
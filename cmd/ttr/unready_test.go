@@ -0,0 +1,121 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newEvent(t *testing.T, namespace, name string, uid types.UID, reason string, last time.Time) runtime.Object {
+	t.Helper()
+	e := &corev1.Event{
+		TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta:     metav1.ObjectMeta{Namespace: namespace, Name: name},
+		InvolvedObject: corev1.ObjectReference{UID: uid},
+		Reason:         reason,
+		LastTimestamp:  metav1.NewTime(last),
+	}
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e)
+	if err != nil {
+		t.Fatalf("failed to convert the event: %v", err)
+	}
+	return &unstructured.Unstructured{Object: o}
+}
+
+func TestLastEventReasons(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		events  []runtime.Object
+		listErr error
+		want    map[types.UID]string
+		wantErr bool
+	}{
+		"LastEventPerObject": {
+			reason: "The reason of the last event of each involved object should be indexed by its UID, in all namespaces.",
+			events: []runtime.Object{
+				newEvent(t, "default", "e1", "uid-1", "CannotObserveExternalResource", testCreated.Add(time.Minute)),
+				newEvent(t, "default", "e2", "uid-1", "CannotCreateExternalResource", testCreated),
+				newEvent(t, "test", "e3", "uid-2", "CreatedExternalResource", testCreated),
+			},
+			want: map[types.UID]string{
+				"uid-1": "CannotObserveExternalResource",
+				"uid-2": "CreatedExternalResource",
+			},
+		},
+		"NoEvents": {
+			reason: "An empty index should be returned if there are no events.",
+			want:   map[types.UID]string{},
+		},
+		"ListError": {
+			reason:  "An error should be returned if the events cannot be listed.",
+			listErr: errors.New("boom"),
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvrEvent: "EventList"}, tc.events...)
+			if tc.listErr != nil {
+				dyn.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.listErr
+				})
+			}
+			got, err := lastEventReasons(context.Background(), dyn, 100)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nlastEventReasons(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nlastEventReasons(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			lists := 0
+			for _, a := range dyn.Actions() {
+				if a.GetVerb() == "list" {
+					lists++
+				}
+			}
+			if lists != 1 {
+				t.Errorf("\n%s\nlastEventReasons(...): got %d event lists, want 1", tc.reason, lists)
+			}
+		})
+	}
+}
+
+func TestNewUnreadyDetails(t *testing.T) {
+	u := newManaged(t, "vpc", condition(xpv1.TypeSynced, corev1.ConditionFalse, 10), condition(xpv1.TypeReady, corev1.ConditionFalse, 10))
+	now := testCreated.Add(2 * time.Minute)
+	want := &unreadyDetails{AgeSeconds: 120, ReadyReason: "Test", SyncedReason: "Test", LastEventReason: "CannotCreateExternalResource"}
+	got := newUnreadyDetails(u, "CannotCreateExternalResource", now)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("newUnreadyDetails(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Ready: Test", "Synced: Test", "Event: CannotCreateExternalResource"}, got.reasons()); diff != "" {
+		t.Errorf("reasons(): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resourceKey identifies a managed resource being watched.
+type resourceKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+func (k resourceKey) String() string {
+	return resourceString(k.gvk, k.namespace, k.name)
+}
+
+// tracker tracks the watched managed resources until the watch ends.
+type tracker interface {
+	// observe handles the addition or update of the specified resource.
+	observe(ctx context.Context, api managedAPI, obj any)
+	// forget handles the removal of the specified resource, which has been
+	// observed at the specified time.
+	forget(ctx context.Context, api managedAPI, obj any, removed time.Time)
+	// summary reports the tracked resources when the watch ends at the
+	// specified time, and returns an error if any of them is still
+	// pending.
+	summary(now time.Time) error
+}
+
+// watcher tracks the readiness of the watched managed resources, reporting
+// each resource as soon as it becomes ready, and each deleted resource as
+// soon as it's removed. The informer event handlers may run concurrently
+// for different APIs.
+type watcher struct {
+	f  filters
+	o  *output
+	mu sync.Mutex
+	// pending are the observed resources that have not become ready yet,
+	// with their creation times.
+	pending map[resourceKey]time.Time
+	// ready are the resources already reported as ready, until they are
+	// removed.
+	ready map[resourceKey]struct{}
+	// readyCount is the number of the times the resources have been
+	// reported as ready, including the removed ones.
+	readyCount int
+}
+
+func newWatcher(f filters, o *output) *watcher {
+	return &watcher{
+		f:       f,
+		o:       o,
+		pending: make(map[resourceKey]time.Time),
+		ready:   make(map[resourceKey]struct{}),
+	}
+}
+
+// observe reports the specified resource if it has just become ready,
+// or otherwise, tracks it as pending.
+func (w *watcher) observe(ctx context.Context, api managedAPI, obj any) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || !w.f.match(api.gvk, u.GetNamespace(), u.GetName(), u.GetLabels()) {
+		return
+	}
+	k := resourceKey{gvk: api.gvk, namespace: u.GetNamespace(), name: u.GetName()}
+	w.mu.Lock()
+	_, reported := w.ready[k]
+	w.mu.Unlock()
+	if reported {
+		return
+	}
+	// the lock is not held while recording, which may look up the provider
+	// pod. The events of an API are handled sequentially, so a resource is
+	// not recorded concurrently.
+	ready, err := w.o.record(ctx, api, u)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to report %s: %v\n", k, err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !ready {
+		w.pending[k] = u.GetCreationTimestamp().Time
+		return
+	}
+	delete(w.pending, k)
+	w.ready[k] = struct{}{}
+	w.readyCount++
+}
+
+// forget stops tracking the specified resource, which has been deleted,
+// and reports its deletion if the removal has been observed at the
+// specified time. A resource re-created with the same name is tracked
+// again.
+func (w *watcher) forget(ctx context.Context, api managedAPI, obj any, removed time.Time) {
+	// the removal time is not known if the deletion has been missed
+	observed := true
+	if t, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = t.Obj
+		observed = false
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	k := resourceKey{gvk: api.gvk, namespace: u.GetNamespace(), name: u.GetName()}
+	w.mu.Lock()
+	delete(w.pending, k)
+	delete(w.ready, k)
+	w.mu.Unlock()
+	if !observed || !w.f.match(api.gvk, u.GetNamespace(), u.GetName(), u.GetLabels()) {
+		return
+	}
+	if err := w.o.recordDeletion(ctx, api, u, removed); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to report the deletion of %s: %v\n", k, err)
+	}
+}
+
+// summary prints the numbers of the resources that have and have not
+// become ready, and the resources that have not become ready with their
+// ages. The resources that have not become ready are also recorded in the
+// output.
+func (w *watcher) summary(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	keys := sortedKeys(w.pending)
+	fmt.Fprintf(os.Stderr, "%d managed resources became ready, %d never became ready\n", w.readyCount, len(keys))
+	for _, k := range keys {
+		w.o.pending(k.gvk)
+		fmt.Fprintf(os.Stderr, "- %s (age: %s)\n", k, now.Sub(w.pending[k]).Round(time.Second))
+	}
+	if len(keys) > 0 {
+		return errors.Errorf("%d managed resources did not become ready", len(keys))
+	}
+	return nil
+}
+
+func sortedKeys(m map[resourceKey]time.Time) []resourceKey {
+	keys := make([]resourceKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// watchAPIs watches the managed resources of the specified APIs with
+// the specified tracker until the context is canceled or the specified
+// timeout expires, if it's non-zero. An error is returned if any of
+// the tracked resources is still pending when the watch ends.
+func watchAPIs(ctx context.Context, apis []managedAPI, t tracker, dyn dynamic.Interface, ns string, tweak dynamicinformer.TweakListOptionsFunc, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// the cluster-scoped APIs are always watched across the cluster, and
+	// the namespaced APIs in the selected namespace.
+	factories := map[string]dynamicinformer.DynamicSharedInformerFactory{}
+	for _, api := range apis {
+		apiNS := api.namespace(ns)
+		if factories[apiNS] == nil {
+			factories[apiNS] = dynamicinformer.NewFilteredDynamicSharedInformerFactory(dyn, 0, apiNS, tweak)
+		}
+		_, err := factories[apiNS].ForResource(api.gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj any) {
+				t.observe(ctx, api, obj)
+			},
+			UpdateFunc: func(_, obj any) {
+				t.observe(ctx, api, obj)
+			},
+			DeleteFunc: func(obj any) {
+				t.forget(ctx, api, obj, time.Now())
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to add the event handler for GVR: %s", api.gvr.String())
+		}
+	}
+	for _, factory := range factories {
+		factory.Start(ctx.Done())
+	}
+	<-ctx.Done()
+	for _, factory := range factories {
+		factory.Shutdown()
+	}
+
+	return t.summary(time.Now())
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	testCreated = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testDeleted = testCreated.Add(time.Hour)
+
+	apiVPC = managedAPI{
+		gvr: schema.GroupVersionResource{Group: "ec2.aws.upbound.io", Version: "v1beta1", Resource: "vpcs"},
+		gvk: schema.GroupVersionKind{Group: "ec2.aws.upbound.io", Version: "v1beta1", Kind: "VPC"},
+	}
+)
+
+// trackerEvent is an informer event handled by a tracker.
+type trackerEvent struct {
+	// removed is the time the removal of the object is observed at. Zero
+	// if the object is added or updated.
+	removed time.Time
+	obj     any
+}
+
+// newManaged returns a managed resource created at testCreated with
+// the specified conditions.
+func newManaged(t *testing.T, name string, conditions ...xpv1.Condition) unstructured.Unstructured {
+	t.Helper()
+	cs := make([]interface{}, 0, len(conditions))
+	for _, c := range conditions {
+		o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&c)
+		if err != nil {
+			t.Fatalf("failed to convert the condition: %v", err)
+		}
+		cs = append(cs, o)
+	}
+	u := unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"conditions": cs},
+	}}
+	u.SetAPIVersion("ec2.aws.upbound.io/v1beta1")
+	u.SetKind("VPC")
+	u.SetName(name)
+	u.SetCreationTimestamp(metav1.NewTime(testCreated))
+	return u
+}
+
+// condition returns a condition of the specified type and status, which
+// transitioned the specified number of seconds after testCreated.
+func condition(ct xpv1.ConditionType, s corev1.ConditionStatus, seconds int) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               ct,
+		Status:             s,
+		Reason:             "Test",
+		LastTransitionTime: metav1.NewTime(testCreated.Add(time.Duration(seconds) * time.Second)),
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	removed := testDeleted.Add(30 * time.Second)
+	synced := func() *unstructured.Unstructured {
+		u := newManaged(t, "vpc", condition(xpv1.TypeSynced, corev1.ConditionTrue, 10))
+		return &u
+	}
+	ready := func() *unstructured.Unstructured {
+		u := newManaged(t, "vpc", condition(xpv1.TypeSynced, corev1.ConditionTrue, 10), condition(xpv1.TypeReady, corev1.ConditionTrue, 30))
+		return &u
+	}
+	inNamespace := func(u *unstructured.Unstructured, ns string) *unstructured.Unstructured {
+		u.SetNamespace(ns)
+		return u
+	}
+	deleted := func(u *unstructured.Unstructured) *unstructured.Unstructured {
+		dt := metav1.NewTime(testDeleted)
+		u.SetDeletionTimestamp(&dt)
+		return u
+	}
+	cases := map[string]struct {
+		reason  string
+		filters []string
+		events  []trackerEvent
+		want    []event
+		wantErr bool
+	}{
+		"BecomesReady": {
+			reason: "A resource should be reported once when it becomes ready.",
+			events: []trackerEvent{{obj: synced()}, {obj: ready()}, {obj: ready()}},
+			want:   []event{eventReady},
+		},
+		"SameNameInNamespaces": {
+			reason: "The resources with the same name in different namespaces should be reported separately.",
+			events: []trackerEvent{{obj: inNamespace(ready(), "a")}, {obj: inNamespace(ready(), "b")}},
+			want:   []event{eventReady, eventReady},
+		},
+		"NeverReady": {
+			reason:  "A resource that never becomes ready should not be reported and an error should be returned.",
+			events:  []trackerEvent{{obj: synced()}},
+			wantErr: true,
+		},
+		"ReadyThenDeleted": {
+			reason: "The removal of a ready resource should be reported.",
+			events: []trackerEvent{{obj: ready()}, {obj: deleted(ready())}, {obj: deleted(ready()), removed: removed}},
+			want:   []event{eventReady, eventDeleted},
+		},
+		"ReadyDeletedRecreated": {
+			reason: "A resource re-created with the same name after its removal should be reported again when it becomes ready.",
+			events: []trackerEvent{{obj: ready()}, {obj: deleted(ready()), removed: removed}, {obj: synced()}, {obj: ready()}},
+			want:   []event{eventReady, eventDeleted, eventReady},
+		},
+		"MissedRemovalRecreated": {
+			reason: "A resource re-created with the same name after its missed removal should be reported again when it becomes ready.",
+			events: []trackerEvent{{obj: ready()}, {obj: cache.DeletedFinalStateUnknown{Key: "vpc", Obj: ready()}, removed: removed}, {obj: ready()}},
+			want:   []event{eventReady, eventReady},
+		},
+		"RemovedWithoutFinalizers": {
+			reason: "The removal of a resource without a deletion timestamp should not be reported.",
+			events: []trackerEvent{{obj: ready()}, {obj: ready(), removed: removed}},
+			want:   []event{eventReady},
+		},
+		"RemovedWhilePending": {
+			reason: "A pending resource that is removed should no longer be pending.",
+			events: []trackerEvent{{obj: synced()}, {obj: deleted(synced()), removed: removed}},
+			want:   []event{eventDeleted},
+		},
+		"MissedRemoval": {
+			reason: "A pending resource whose removal has been missed should neither be reported nor pending.",
+			events: []trackerEvent{{obj: synced()}, {obj: cache.DeletedFinalStateUnknown{Key: "vpc", Obj: deleted(synced())}, removed: removed}},
+		},
+		"Filtered": {
+			reason:  "A resource not matching the filters should be neither reported nor pending.",
+			filters: []string{"//Subnet/"},
+			events:  []trackerEvent{{obj: synced()}, {obj: ready()}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := getFilters(tc.filters...)
+			if err != nil {
+				t.Fatalf("getFilters(...): unexpected error: %v", err)
+			}
+			r := &structuredReporter{}
+			w := newWatcher(f, &output{r: r})
+			for _, e := range tc.events {
+				if e.removed.IsZero() {
+					w.observe(context.Background(), apiVPC, e.obj)
+					continue
+				}
+				w.forget(context.Background(), apiVPC, e.obj, e.removed)
+			}
+			if err := w.summary(removed); (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nsummary(...): unexpected error: %v", tc.reason, err)
+			}
+			var got []event
+			for _, m := range r.measurements {
+				got = append(got, m.Event)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nobserve/forget(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
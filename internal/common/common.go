@@ -17,6 +17,8 @@ package common
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -102,6 +104,52 @@ func CalculateAverageAndPeak(data []Data) (float64, float64) {
 	return sum / float64(len(data)), peak
 }
 
+// Statistics are the summary statistics of a set of collected data
+type Statistics struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+// CalculateStatistics calculates the summary statistics of the collected
+// data. The mean and the maximum are calculated with CalculateAverageAndPeak
+// and the percentiles with the nearest-rank method. The zero value is
+// returned for empty data.
+func CalculateStatistics(data []Data) Statistics {
+	if len(data) == 0 {
+		return Statistics{}
+	}
+	values := make([]float64, len(data))
+	for i, d := range data {
+		values[i] = d.Value
+	}
+	sort.Float64s(values)
+	s := Statistics{
+		Count: len(values),
+		Min:   values[0],
+		Max:   values[len(values)-1],
+		P50:   percentile(values, 50),
+		P90:   percentile(values, 90),
+		P99:   percentile(values, 99),
+	}
+	s.Mean, _ = CalculateAverageAndPeak(data)
+	return s
+}
+
+// percentile returns the p-th percentile of the sorted values using the
+// nearest-rank method.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
 // Print reports the results
 func (r Result) Print() {
 	if r.PodName != "" {
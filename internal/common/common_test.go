@@ -0,0 +1,67 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCalculateStatistics(t *testing.T) {
+	hundred := make([]Data, 0, 100)
+	for i := 100; i > 0; i-- {
+		hundred = append(hundred, Data{Value: float64(i)})
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   []Data
+		want   Statistics
+	}{
+		"Empty": {
+			reason: "The zero value should be returned for empty data.",
+			want:   Statistics{},
+		},
+		"Single": {
+			reason: "All the statistics of a single value should be that value.",
+			data:   []Data{{Value: 42}},
+			want:   Statistics{Count: 1, Min: 42, Max: 42, Mean: 42, P50: 42, P90: 42, P99: 42},
+		},
+		"Unsorted": {
+			reason: "The percentiles of unsorted data should be calculated with the nearest-rank method.",
+			data:   []Data{{Value: 30}, {Value: 10}, {Value: 40}, {Value: 20}},
+			want:   Statistics{Count: 4, Min: 10, Max: 40, Mean: 25, P50: 20, P90: 40, P99: 40},
+		},
+		"Hundred": {
+			reason: "The percentiles of the values from 1 to 100 should be the values themselves.",
+			data:   hundred,
+			want:   Statistics{Count: 100, Min: 1, Max: 100, Mean: 50.5, P50: 50, P90: 90, P99: 99},
+		},
+		"AllNegative": {
+			reason: "The maximum of all-negative data should be the greatest value rather than zero.",
+			data:   []Data{{Value: -3}, {Value: -1}, {Value: -2}},
+			want:   Statistics{Count: 3, Min: -3, Max: -1, Mean: -2, P50: -2, P90: -1, P99: -1},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := CalculateStatistics(tc.data)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCalculateStatistics(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
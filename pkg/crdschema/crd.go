@@ -25,11 +25,11 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/oasdiff/oasdiff/diff"
-	"github.com/oasdiff/oasdiff/report"
 	"github.com/oasdiff/oasdiff/utils"
 	"github.com/pkg/errors"
 	"golang.org/x/mod/semver"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	apiyaml "k8s.io/apimachinery/pkg/util/yaml"
 	k8syaml "sigs.k8s.io/yaml"
 )
@@ -63,9 +63,12 @@ type SchemaCheck interface {
 // RevisionDiff can compute schema changes between the base CRD found at `basePath`
 // and the revision CRD found at `revisionPath`.
 type RevisionDiff struct {
-	baseCRD       *v1.CustomResourceDefinition
-	revisionCRD   *v1.CustomResourceDefinition
-	commonOptions CommonOptions
+	baseCRD        *v1.CustomResourceDefinition
+	revisionCRD    *v1.CustomResourceDefinition
+	baseSource     SourceOptions
+	revisionSource SourceOptions
+	policy         Policy
+	commonOptions  CommonOptions
 }
 
 // RevisionDiffOption is a functional option to configure the behavior of
@@ -80,9 +83,38 @@ func WithRevisionDiffCommonOptions(opts *CommonOptions) RevisionDiffOption {
 	}
 }
 
+// WithBaseSource configures how the base schema input is loaded.
+// By default, the base is loaded from a CRD manifest file.
+func WithBaseSource(src SourceOptions) RevisionDiffOption {
+	return func(rd *RevisionDiff) {
+		rd.baseSource = src
+	}
+}
+
+// WithRevisionSource configures how the revision schema input is loaded.
+// By default, the revision is loaded from a CRD manifest file.
+func WithRevisionSource(src SourceOptions) RevisionDiffOption {
+	return func(rd *RevisionDiff) {
+		rd.revisionSource = src
+	}
+}
+
+// WithPolicy configures the policy a RevisionDiff checks the changes
+// against. With PolicyKubernetes, the base and revision versions are
+// paired by their names and the versions removed in the revision are
+// reported with all their top-level fields deleted and left to the policy
+// check instead of failing the diff.
+func WithPolicy(p Policy) RevisionDiffOption {
+	return func(rd *RevisionDiff) {
+		rd.policy = p
+	}
+}
+
 // NewRevisionDiff returns a new RevisionDiff initialized with
 // the base and revision CRDs loaded from the specified
-// base and revision CRD paths.
+// base and revision paths. The paths are CRD manifest files
+// unless configured otherwise with WithBaseSource or
+// WithRevisionSource.
 func NewRevisionDiff(basePath, revisionPath string, opts ...RevisionDiffOption) (*RevisionDiff, error) {
 	d := &RevisionDiff{}
 	for _, o := range opts {
@@ -90,11 +122,11 @@ func NewRevisionDiff(basePath, revisionPath string, opts ...RevisionDiffOption)
 	}
 
 	var err error
-	d.baseCRD, err = loadCRD(basePath, d.commonOptions.EnableUpjetExtensions)
+	d.baseCRD, err = loadSource(basePath, d.baseSource, d.commonOptions.EnableUpjetExtensions)
 	if err != nil {
 		return nil, errors.Wrap(err, errCRDLoad)
 	}
-	d.revisionCRD, err = loadCRD(revisionPath, d.commonOptions.EnableUpjetExtensions)
+	d.revisionCRD, err = loadSource(revisionPath, d.revisionSource, d.commonOptions.EnableUpjetExtensions)
 	if err != nil {
 		return nil, errors.Wrap(err, errCRDLoad)
 	}
@@ -105,6 +137,7 @@ func NewRevisionDiff(basePath, revisionPath string, opts ...RevisionDiffOption)
 // declared for a CRD.
 type SelfDiff struct {
 	crd           *v1.CustomResourceDefinition
+	policy        Policy
 	commonOptions CommonOptions
 }
 
@@ -120,6 +153,14 @@ func WithSelfDiffCommonOptions(opts *CommonOptions) SelfDiffOption {
 	}
 }
 
+// WithSelfDiffPolicy configures the policy a SelfDiff checks the versions
+// of the CRD against.
+func WithSelfDiffPolicy(p Policy) SelfDiffOption {
+	return func(sd *SelfDiff) {
+		sd.policy = p
+	}
+}
+
 // NewSelfDiff returns a new SelfDiff initialized with a CRD loaded
 // from the specified path.
 func NewSelfDiff(crdPath string, opts ...SelfDiffOption) (*SelfDiff, error) {
@@ -140,6 +181,20 @@ func (d *SelfDiff) GetCRD() *v1.CustomResourceDefinition {
 	return d.crd
 }
 
+// GroupKind returns the API group and the kind of the CRD.
+func (d *SelfDiff) GroupKind() schema.GroupKind {
+	return crdGroupKind(d.crd)
+}
+
+// GroupKind returns the API group and the kind of the revision CRD.
+func (d *RevisionDiff) GroupKind() schema.GroupKind {
+	return crdGroupKind(d.revisionCRD)
+}
+
+func crdGroupKind(crd *v1.CustomResourceDefinition) schema.GroupKind {
+	return schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+}
+
 func loadCRD(m string, enableUpjetExtensions bool) (*v1.CustomResourceDefinition, error) {
 	crd := &v1.CustomResourceDefinition{}
 	buff, err := os.ReadFile(filepath.Clean(m))
@@ -150,12 +205,16 @@ func loadCRD(m string, enableUpjetExtensions bool) (*v1.CustomResourceDefinition
 		return nil, errors.Wrapf(err, "failed to unmarshal CRD manifest from file: %s", m)
 	}
 
+	return crd, errors.Wrapf(applyExtensions(crd, enableUpjetExtensions), "failed to apply the diff extensions to the CRD manifest from file: %s", m)
+}
+
+func applyExtensions(crd *v1.CustomResourceDefinition, enableUpjetExtensions bool) error {
 	if enableUpjetExtensions {
 		if err := injectUpjetXKubernetesValidationRules(crd); err != nil {
-			return nil, errors.Wrapf(err, "failed to inject upjet's x-kubernetes-validations imposed required rules")
+			return errors.Wrapf(err, "failed to inject upjet's x-kubernetes-validations imposed required rules")
 		}
 	}
-	return crd, nil
+	return nil
 }
 
 func injectUpjetXKubernetesValidationRules(crd *v1.CustomResourceDefinition) error {
@@ -195,28 +254,8 @@ func getOpenAPIv3Document(crd *v1.CustomResourceDefinition) ([]*openapi3.T, erro
 		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
 			return nil, errors.Errorf("invalid CRD manifest: CRD's .Spec.Versions[%q].Schema.OpenAPIV3Schema cannot be nil", v.Name)
 		}
-		t := &openapi3.T{
-			Info: &openapi3.Info{
-				Version: v.Name,
-			},
-			Paths: openapi3.NewPaths(),
-		}
-		c := openapi3.NewContent()
-		t.Paths.Set("/crd", &openapi3.PathItem{
-			Put: &openapi3.Operation{
-				RequestBody: &openapi3.RequestBodyRef{
-					Value: &openapi3.RequestBody{
-						Content: c,
-					},
-				},
-			},
-		})
 		s := &openapi3.Schema{}
-		c[contentTypeJSON] = &openapi3.MediaType{
-			Schema: &openapi3.SchemaRef{
-				Value: s,
-			},
-		}
+		t := newOpenAPIv3Document(v.Name, s)
 
 		// convert from CRD validation schema to openAPI v3 schema
 		buff, err := k8syaml.Marshal(v.Schema.OpenAPIV3Schema)
@@ -231,6 +270,34 @@ func getOpenAPIv3Document(crd *v1.CustomResourceDefinition) ([]*openapi3.T, erro
 	return schemas, nil
 }
 
+// newOpenAPIv3Document returns an OpenAPI v3 document for the CRD version
+// with the specified name, which accepts the specified schema as
+// the request body of the PUT /crd endpoint.
+func newOpenAPIv3Document(version string, s *openapi3.Schema) *openapi3.T {
+	t := &openapi3.T{
+		Info: &openapi3.Info{
+			Version: version,
+		},
+		Paths: openapi3.NewPaths(),
+	}
+	c := openapi3.NewContent()
+	t.Paths.Set("/crd", &openapi3.PathItem{
+		Put: &openapi3.Operation{
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Content: c,
+				},
+			},
+		},
+	})
+	c[contentTypeJSON] = &openapi3.MediaType{
+		Schema: &openapi3.SchemaRef{
+			Value: s,
+		},
+	}
+	return t
+}
+
 // GetBreakingChanges returns the breaking changes found in the
 // consecutive versions of a CRD. This method always filters non-breaking
 // changes - use GetRawDiff() if you need all changes.
@@ -253,7 +320,8 @@ func GetChangesAsStructured(rawDiff map[string]*diff.Diff, keepAllChanges bool)
 		Versions: make(map[string]*VersionChanges),
 	}
 
-	for newVersion, diffData := range rawDiff {
+	for _, newVersion := range SortedVersionNames(rawDiff) {
+		diffData := rawDiff[newVersion]
 		var oldVersion string
 		if diffData != nil && diffData.InfoDiff != nil && diffData.InfoDiff.VersionDiff != nil {
 			oldVersion = diffData.InfoDiff.VersionDiff.From.(string)
@@ -269,9 +337,26 @@ func GetChangesAsStructured(rawDiff map[string]*diff.Diff, keepAllChanges bool)
 		}
 	}
 
+	fp, err := r.ComputeFingerprint()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute the change report fingerprint")
+	}
+	r.Fingerprint = fp
 	return r, nil
 }
 
+// SortedVersionNames returns the version names in the specified diff map
+// in a deterministic order, so that reports iterating over the versions
+// are stable across runs.
+func SortedVersionNames(diffMap map[string]*diff.Diff) []string {
+	names := make([]string, 0, len(diffMap))
+	for v := range diffMap {
+		names = append(names, v)
+	}
+	semver.Sort(names)
+	return names
+}
+
 // GetRawDiff computes the raw diff between consecutive versions in a CRD.
 // It returns unfiltered changes - use GetBreakingChanges() for filtered results.
 func (d *SelfDiff) GetRawDiff() (map[string]*diff.Diff, error) {
@@ -337,6 +422,10 @@ func (d *RevisionDiff) GetRawDiff() (map[string]*diff.Diff, error) {
 		return nil, errors.Wrap(err, errBreakingRevisionChangesCompute)
 	}
 
+	if d.policy == PolicyKubernetes {
+		return diffByVersionName(baseDocs, revisionDocs)
+	}
+
 	diffMap := make(map[string]*diff.Diff, len(baseDocs))
 	for i, baseDoc := range baseDocs {
 		versionName := baseDoc.Info.Version
@@ -353,83 +442,222 @@ func (d *RevisionDiff) GetRawDiff() (map[string]*diff.Diff, error) {
 	return diffMap, nil
 }
 
+// diffByVersionName computes the diffs between the base and revision
+// versions with the same names. The base versions without a counterpart
+// in the revision are reported as removed, i.e., all their top-level
+// fields are deleted.
+func diffByVersionName(baseDocs, revisionDocs []*openapi3.T) (map[string]*diff.Diff, error) {
+	revisions := make(map[string]*openapi3.T, len(revisionDocs))
+	for _, rd := range revisionDocs {
+		revisions[rd.Info.Version] = rd
+	}
+	diffMap := make(map[string]*diff.Diff, len(baseDocs))
+	for _, baseDoc := range baseDocs {
+		versionName := baseDoc.Info.Version
+		revisionDoc, ok := revisions[versionName]
+		if !ok {
+			revisionDoc = removedVersionDocument(baseDoc)
+		}
+		sd, err := schemaDiff(baseDoc, revisionDoc)
+		if err != nil {
+			return nil, errors.Wrap(err, errBreakingRevisionChangesCompute)
+		}
+		diffMap[versionName] = sd
+	}
+	return diffMap, nil
+}
+
+// removedVersionDocument returns the document of the version of
+// the specified base document as if it's removed, i.e., with a schema
+// without any properties.
+func removedVersionDocument(baseDoc *openapi3.T) *openapi3.T {
+	s := &openapi3.Schema{}
+	if mt := baseDoc.Paths.Value("/crd").Put.RequestBody.Value.Content.Get(contentTypeJSON); mt != nil && mt.Schema != nil && mt.Schema.Value != nil {
+		*s = *mt.Schema.Value
+		s.Properties = nil
+		s.Required = nil
+	}
+	return newOpenAPIv3Document(baseDoc.Info.Version, s)
+}
+
 var crdPutEndpoint = diff.Endpoint{
 	Method: "PUT",
 	Path:   "/crd",
 }
 
-func filterNonBreaking(diffMap map[string]*diff.Diff) map[string]*diff.Diff { //nolint:gocyclo // sequential flow easier to follow
+// filterNonBreaking returns the diffs without the non-breaking changes.
+// The specified diffs are not modified, so they can still be used for
+// reporting all the changes: the filtered diffs share the unmodified parts
+// with them and copy the modified ones.
+func filterNonBreaking(diffMap map[string]*diff.Diff) map[string]*diff.Diff {
+	filtered := make(map[string]*diff.Diff, len(diffMap))
 	for v, d := range diffMap {
-		if d == nil || d.Empty() {
-			continue
-		}
-		if d.EndpointsDiff == nil {
-			continue
-		}
-		methodDiff, ok := d.EndpointsDiff.Modified[crdPutEndpoint]
-		if !ok || methodDiff == nil || methodDiff.RequestBodyDiff == nil || methodDiff.RequestBodyDiff.ContentDiff == nil {
-			continue
-		}
-		mediaType, ok := methodDiff.RequestBodyDiff.ContentDiff.MediaTypeModified[contentTypeJSON]
-		if !ok || mediaType == nil {
+		mediaType := crdMediaTypeDiff(d)
+		if mediaType == nil {
+			filtered[v] = d
 			continue
 		}
-		sd := mediaType.SchemaDiff
-		ignoreOptionalNewProperties(sd)
+		sd := ignoreOptionalNewProperties(mediaType.SchemaDiff)
 		if sd != nil && empty(sd.PropertiesDiff) {
-			sd.PropertiesDiff = nil
+			c := *sd
+			c.PropertiesDiff = nil
+			sd = &c
 		}
 		if sd == nil || sd.Empty() {
-			delete(diffMap, v)
+			continue
 		}
+		filtered[v] = withCRDSchemaDiff(d, sd)
+	}
+	return filtered
+}
+
+// crdMediaTypeDiff returns the diff of the CRD schema's media type in
+// the specified diff, or nil if the schema has not changed.
+func crdMediaTypeDiff(d *diff.Diff) *diff.MediaTypeDiff {
+	if d == nil || d.Empty() || d.EndpointsDiff == nil {
+		return nil
+	}
+	methodDiff, ok := d.EndpointsDiff.Modified[crdPutEndpoint]
+	if !ok || methodDiff == nil || methodDiff.RequestBodyDiff == nil || methodDiff.RequestBodyDiff.ContentDiff == nil {
+		return nil
 	}
-	return diffMap
+	return methodDiff.RequestBodyDiff.ContentDiff.MediaTypeModified[contentTypeJSON]
 }
 
-func ignoreOptionalNewProperties(sd *diff.SchemaDiff) {
+// withCRDSchemaDiff returns a copy of the specified diff with the specified
+// CRD schema diff. Only the parts of the diff on the path to the schema
+// diff are copied.
+func withCRDSchemaDiff(d *diff.Diff, sd *diff.SchemaDiff) *diff.Diff {
+	methodDiff := d.EndpointsDiff.Modified[crdPutEndpoint]
+	mediaType := *methodDiff.RequestBodyDiff.ContentDiff.MediaTypeModified[contentTypeJSON]
+	mediaType.SchemaDiff = sd
+
+	contentDiff := *methodDiff.RequestBodyDiff.ContentDiff
+	contentDiff.MediaTypeModified = make(diff.ModifiedMediaTypes, len(contentDiff.MediaTypeModified))
+	for k, v := range methodDiff.RequestBodyDiff.ContentDiff.MediaTypeModified {
+		contentDiff.MediaTypeModified[k] = v
+	}
+	contentDiff.MediaTypeModified[contentTypeJSON] = &mediaType
+
+	requestBodyDiff := *methodDiff.RequestBodyDiff
+	requestBodyDiff.ContentDiff = &contentDiff
+	md := *methodDiff
+	md.RequestBodyDiff = &requestBodyDiff
+
+	endpointsDiff := *d.EndpointsDiff
+	endpointsDiff.Modified = make(diff.ModifiedEndpoints, len(endpointsDiff.Modified))
+	for k, v := range d.EndpointsDiff.Modified {
+		endpointsDiff.Modified[k] = v
+	}
+	endpointsDiff.Modified[crdPutEndpoint] = &md
+
+	c := *d
+	c.EndpointsDiff = &endpointsDiff
+	return &c
+}
+
+// ignoreOptionalNewProperties returns a copy of the specified schema diff
+// without the new optional properties, which are non-breaking. The same
+// subschemas as in FlattenDiff are walked, i.e., the array items, the map
+// values, and the allOf, oneOf, anyOf and not subschemas.
+func ignoreOptionalNewProperties(sd *diff.SchemaDiff) *diff.SchemaDiff {
 	if sd == nil || sd.Empty() {
-		return
+		return sd
+	}
+	c := *sd
+	c.PropertiesDiff = ignorePropertiesDiff(sd)
+	c.ItemsDiff = ignoreOptionalNewSubschemaProperties(sd.ItemsDiff)
+	c.AdditionalPropertiesDiff = ignoreOptionalNewSubschemaProperties(sd.AdditionalPropertiesDiff)
+	c.NotDiff = ignoreOptionalNewSubschemaProperties(sd.NotDiff)
+	c.AllOfDiff = ignoreSubschemasDiff(sd.AllOfDiff)
+	c.OneOfDiff = ignoreSubschemasDiff(sd.OneOfDiff)
+	c.AnyOfDiff = ignoreSubschemasDiff(sd.AnyOfDiff)
+	return &c
+}
+
+// ignoreOptionalNewSubschemaProperties returns a copy of the specified
+// subschema diff without the new optional properties, or nil if there are
+// no other changes.
+func ignoreOptionalNewSubschemaProperties(sd *diff.SchemaDiff) *diff.SchemaDiff {
+	sd = ignoreOptionalNewProperties(sd)
+	if sd != nil && sd.Empty() {
+		return nil
 	}
-	ignorePropertiesDiff(sd)
-	ignoreOptionalNewProperties(sd.ItemsDiff)
-	if sd.ItemsDiff != nil && sd.ItemsDiff.Empty() {
-		sd.ItemsDiff = nil
+	return sd
+}
+
+// ignoreSubschemasDiff returns a copy of the specified allOf, oneOf or
+// anyOf diff without the new optional properties of the modified
+// subschemas, or nil if there are no other changes.
+func ignoreSubschemasDiff(ssd *diff.SubschemasDiff) *diff.SubschemasDiff {
+	if ssd.Empty() {
+		return ssd
+	}
+	c := *ssd
+	c.Modified = make(diff.ModifiedSubschemas, 0, len(ssd.Modified))
+	for _, m := range ssd.Modified {
+		if m == nil {
+			continue
+		}
+		sd := ignoreOptionalNewSubschemaProperties(m.Diff)
+		if sd == nil {
+			continue
+		}
+		mc := *m
+		mc.Diff = sd
+		c.Modified = append(c.Modified, &mc)
+	}
+	if c.Empty() {
+		return nil
 	}
+	return &c
 }
 
-func ignorePropertiesDiff(sd *diff.SchemaDiff) {
+// ignorePropertiesDiff returns a copy of the properties diff of
+// the specified schema diff without the new optional properties, or nil if
+// there are no other property changes.
+func ignorePropertiesDiff(sd *diff.SchemaDiff) *diff.SchemasDiff {
 	if sd.PropertiesDiff == nil {
-		return
+		return nil
 	}
-	keepOptionalNewFieldsDiff(sd)
+	pd := *sd.PropertiesDiff
+	pd.Added = requiredNewFields(sd)
+	pd.Modified = make(diff.ModifiedSchemasMap, len(sd.PropertiesDiff.Modified))
 	for n, csd := range sd.PropertiesDiff.Modified {
-		ignoreOptionalNewProperties(csd)
-		if csd != nil && empty(csd.PropertiesDiff) {
-			csd.PropertiesDiff = nil
-		}
+		csd = ignoreOptionalNewProperties(csd)
 		if csd == nil || csd.Empty() {
-			delete(sd.PropertiesDiff.Modified, n)
+			continue
+		}
+		if empty(csd.PropertiesDiff) {
+			c := *csd
+			c.PropertiesDiff = nil
+			csd = &c
+		}
+		if !csd.Empty() {
+			pd.Modified[n] = csd
 		}
 	}
-	if empty(sd.PropertiesDiff) {
-		sd.PropertiesDiff = nil
+	if empty(&pd) {
+		return nil
 	}
+	return &pd
 }
 
-func keepOptionalNewFieldsDiff(sd *diff.SchemaDiff) {
-	// optional new fields are non-breaking
-	filteredAddedProps := make(utils.StringList, 0, len(sd.PropertiesDiff.Added))
+// requiredNewFields returns the new properties of the specified schema
+// diff that are also required. The optional new fields are non-breaking.
+func requiredNewFields(sd *diff.SchemaDiff) utils.StringList {
+	added := make(utils.StringList, 0, len(sd.PropertiesDiff.Added))
 	if sd.RequiredDiff != nil {
 		for _, f := range sd.PropertiesDiff.Added {
 			for _, r := range sd.RequiredDiff.Added {
 				if f == r {
-					filteredAddedProps = append(filteredAddedProps, f)
+					added = append(added, f)
 					break
 				}
 			}
 		}
 	}
-	sd.PropertiesDiff.Added = filteredAddedProps
+	return added
 }
 
 func empty(sd *diff.SchemasDiff) bool {
@@ -457,14 +685,7 @@ func schemaDiff(baseDoc, revisionDoc *openapi3.T) (*diff.Diff, error) {
 }
 
 // GetDiffReport is a utility function to format the specified diff as a string
+// in the verbose text mode without colors.
 func GetDiffReport(d *diff.Diff) string {
-	if d.Empty() {
-		return ""
-	}
-	l := strings.Split(report.GetTextReportAsString(d), "\n")
-	l = l[12:]
-	for i, s := range l {
-		l[i] = strings.TrimPrefix(s, "      ")
-	}
-	return strings.Join(l, "\n")
+	return strings.TrimSuffix(NewTextRenderer().RenderString(d), "\n")
 }
@@ -367,6 +367,148 @@ func Test_GetRevisionBreakingChanges(t *testing.T) {
 	}
 }
 
+func Test_GetRevisionBreakingChangesSubschemas(t *testing.T) {
+	branch := func(required ...string) v1.JSONSchemaProps {
+		return v1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]v1.JSONSchemaProps{
+				"name": {Type: "string"},
+			},
+			Required: required,
+		}
+	}
+	withNewField := func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+		b.Properties["newField"] = v1.JSONSchemaProps{Type: "string"}
+		return b
+	}
+	type args struct {
+		// wrap returns the schema of the field containing the specified
+		// subschema.
+		wrap     func(b v1.JSONSchemaProps) v1.JSONSchemaProps
+		revision v1.JSONSchemaProps
+	}
+	tests := map[string]struct {
+		reason   string
+		args     args
+		breaking bool
+	}{
+		"NewOptionalFieldInAdditionalProperties": {
+			reason: "No diff should be reported if the map values acquire a new optional field",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{Type: "object", AdditionalProperties: &v1.JSONSchemaPropsOrBool{Allows: true, Schema: &b}}
+				},
+				revision: withNewField(branch()),
+			},
+		},
+		"NewRequiredFieldInAdditionalProperties": {
+			reason: "A new required field in the map values is a breaking API change",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{Type: "object", AdditionalProperties: &v1.JSONSchemaPropsOrBool{Allows: true, Schema: &b}}
+				},
+				revision: withNewField(branch("newField")),
+			},
+			breaking: true,
+		},
+		"NewOptionalFieldInAllOf": {
+			reason: "No diff should be reported if an allOf branch acquires a new optional field",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{AllOf: []v1.JSONSchemaProps{b}}
+				},
+				revision: withNewField(branch()),
+			},
+		},
+		"NewRequiredFieldInAllOf": {
+			reason: "A new required field in an allOf branch is a breaking API change",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{AllOf: []v1.JSONSchemaProps{b}}
+				},
+				revision: withNewField(branch("newField")),
+			},
+			breaking: true,
+		},
+		"NewOptionalFieldInOneOf": {
+			reason: "No diff should be reported if a oneOf branch acquires a new optional field",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{OneOf: []v1.JSONSchemaProps{b}}
+				},
+				revision: withNewField(branch()),
+			},
+		},
+		"NewRequiredFieldInOneOf": {
+			reason: "A new required field in a oneOf branch is a breaking API change",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{OneOf: []v1.JSONSchemaProps{b}}
+				},
+				revision: withNewField(branch("newField")),
+			},
+			breaking: true,
+		},
+		"NewOptionalFieldInAnyOf": {
+			reason: "No diff should be reported if an anyOf branch acquires a new optional field",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{AnyOf: []v1.JSONSchemaProps{b}}
+				},
+				revision: withNewField(branch()),
+			},
+		},
+		"NewRequiredFieldInAnyOf": {
+			reason: "A new required field in an anyOf branch is a breaking API change",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{AnyOf: []v1.JSONSchemaProps{b}}
+				},
+				revision: withNewField(branch("newField")),
+			},
+			breaking: true,
+		},
+		"NewOptionalFieldInNot": {
+			reason: "No diff should be reported if a not subschema acquires a new optional field",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{Not: &b}
+				},
+				revision: withNewField(branch()),
+			},
+		},
+		"NewRequiredFieldInNot": {
+			reason: "A new required field in a not subschema is a breaking API change",
+			args: args{
+				wrap: func(b v1.JSONSchemaProps) v1.JSONSchemaProps {
+					return v1.JSONSchemaProps{Not: &b}
+				},
+				revision: withNewField(branch("newField")),
+			},
+			breaking: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil, func(r *v1.CustomResourceDefinition) {
+				addSpecForProviderProperty(r, 0, "branched", tt.args.wrap(tt.args.revision), nil)
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nnewRevisionDiffWithModifiers(...): failed to load base or revision CRD:\n%v", tt.reason, err)
+			}
+			addSpecForProviderProperty(d.baseCRD, 0, "branched", tt.args.wrap(branch()), nil)
+			m, err := d.GetBreakingChanges()
+			if err != nil {
+				t.Fatalf("\n%s\nGetBreakingChanges(): unexpected error: %v", tt.reason, err)
+			}
+			version := d.baseCRD.Spec.Versions[0].Name
+			if got := !m[version].Empty(); got != tt.breaking {
+				t.Errorf("\n%s\nGetBreakingChanges(): breaking = %v, want %v, diff = \n%s", tt.reason, got, tt.breaking, GetDiffReport(m[version]))
+			}
+		})
+	}
+}
+
 func Test_GetSelfBreakingChanges(t *testing.T) {
 	type want struct {
 		errExpected     bool
@@ -0,0 +1,250 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/oasdiff/oasdiff/diff"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	errDiffSetCanceled = "diff set computation canceled"
+)
+
+// DiffPair is a named pair of base and revision CRD manifests to be
+// compared by a DiffSet.
+type DiffPair struct {
+	// Name identifies the pair in the results, e.g., the CRD file name
+	// relative to the compared directories.
+	Name string
+	// BasePath is the manifest file path of the base CRD.
+	BasePath string
+	// RevisionPath is the manifest file path of the revision CRD.
+	RevisionPath string
+}
+
+// DiffResult is the outcome of comparing a single DiffPair.
+type DiffResult struct {
+	// Pair is the compared DiffPair.
+	Pair DiffPair
+	// Diff maps the CRD version names to their computed diffs. If the
+	// DiffSet is configured to keep all changes, these are the raw
+	// diffs, otherwise only the breaking changes are kept.
+	Diff map[string]*diff.Diff
+	// HasBreakingChanges is true if any breaking changes have been
+	// detected between the base and the revision CRDs.
+	HasBreakingChanges bool
+	// PolicyViolations are the changes violating the policy configured
+	// for the DiffSet, if any.
+	PolicyViolations []PolicyViolation
+	// GroupKind is the API group and the kind of the revision CRD.
+	GroupKind schema.GroupKind
+	// Counts are the numbers of the breaking and non-breaking changes
+	// regardless of whether the DiffSet keeps all changes.
+	Counts ChangeCounts
+	// Err is the error encountered while loading or comparing the pair,
+	// if any. An error in one pair does not abort the other pairs.
+	Err error
+}
+
+// DiffSet computes the schema changes between many base and revision
+// CRD pairs on a bounded pool of workers.
+type DiffSet struct {
+	pairs          []DiffPair
+	parallelism    int
+	keepAllChanges bool
+	policy         Policy
+	commonOptions  CommonOptions
+}
+
+// DiffSetOption is a functional option to configure the behavior of
+// a DiffSet.
+type DiffSetOption func(*DiffSet)
+
+// WithDiffSetParallelism configures the maximum number of pairs that
+// are compared concurrently. Non-positive values are ignored.
+func WithDiffSetParallelism(n int) DiffSetOption {
+	return func(ds *DiffSet) {
+		if n > 0 {
+			ds.parallelism = n
+		}
+	}
+}
+
+// WithDiffSetKeepAllChanges configures whether the non-breaking changes
+// are kept in the computed diffs.
+func WithDiffSetKeepAllChanges(keep bool) DiffSetOption {
+	return func(ds *DiffSet) {
+		ds.keepAllChanges = keep
+	}
+}
+
+// WithDiffSetPolicy configures the policy the changes of each pair are
+// checked against.
+func WithDiffSetPolicy(p Policy) DiffSetOption {
+	return func(ds *DiffSet) {
+		ds.policy = p
+	}
+}
+
+// WithDiffSetCommonOptions configures the common diff options for
+// the pairs of a DiffSet.
+func WithDiffSetCommonOptions(opts *CommonOptions) DiffSetOption {
+	return func(ds *DiffSet) {
+		ds.commonOptions = *opts
+	}
+}
+
+// NewDiffSet returns a new DiffSet for the specified pairs. By default,
+// the number of concurrently compared pairs equals the number of
+// available CPUs.
+func NewDiffSet(pairs []DiffPair, opts ...DiffSetOption) *DiffSet {
+	ds := &DiffSet{
+		pairs:       pairs,
+		parallelism: runtime.NumCPU(),
+	}
+	for _, o := range opts {
+		o(ds)
+	}
+	return ds
+}
+
+// Run compares all the pairs in the set. The returned results are in the
+// same order as the pairs the set was constructed with, regardless of
+// the order in which the comparisons complete. Per-pair failures are
+// reported via DiffResult.Err. If the context is canceled, the pairs not
+// yet compared are reported with the context's error and an error is
+// also returned.
+func (ds *DiffSet) Run(ctx context.Context) ([]DiffResult, error) {
+	results := make([]DiffResult, len(ds.pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(ds.parallelism, len(ds.pairs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = ds.diffPair(ctx, ds.pairs[i])
+			}
+		}()
+	}
+
+	var err error
+	for i := range ds.pairs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = errors.Wrap(ctx.Err(), errDiffSetCanceled)
+			for j := i; j < len(ds.pairs); j++ {
+				results[j] = DiffResult{Pair: ds.pairs[j], Err: ctx.Err()}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results, err
+}
+
+func (ds *DiffSet) diffPair(ctx context.Context, p DiffPair) DiffResult {
+	r := DiffResult{Pair: p}
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+	rd, err := NewRevisionDiff(p.BasePath, p.RevisionPath, WithRevisionDiffCommonOptions(&ds.commonOptions), WithPolicy(ds.policy))
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	r.GroupKind = rd.GroupKind()
+	// the raw diff is computed once and the breaking changes, the counts
+	// and the policy violations are all derived from it.
+	c, err := GetChanges(rd)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if r.PolicyViolations, err = rd.CheckPolicyChanges(c.Breaking); err != nil {
+		r.Err = err
+		return r
+	}
+	r.Counts = c.Counts
+	r.HasBreakingChanges = c.HasBreakingChanges()
+	r.Diff = c.Diff(ds.keepAllChanges)
+	return r
+}
+
+func emptyDiffMap(m map[string]*diff.Diff) bool {
+	for _, d := range m {
+		if d != nil && !d.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffPairsFromDirs returns the DiffPairs for the CRD manifests found
+// under the base directory, paired with the manifests at the same
+// relative paths under the revision directory. The pairs are named
+// with the relative paths and are sorted by name. A base manifest
+// without a counterpart in the revision directory is still paired so
+// that its removal is reported as an error when the set is run.
+// Manifests only found in the revision directory are ignored
+// as new CRDs cannot break existing clients.
+func DiffPairsFromDirs(baseDir, revisionDir string) ([]DiffPair, error) {
+	var pairs []DiffPair
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isYAMLFile(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compute the relative path for: %s", path)
+		}
+		pairs = append(pairs, DiffPair{
+			Name:         filepath.ToSlash(rel),
+			BasePath:     path,
+			RevisionPath: filepath.Join(revisionDir, rel),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to walk the base directory: %s", baseDir)
+	}
+	if _, err := os.Stat(revisionDir); err != nil {
+		return nil, errors.Wrapf(err, "failed to stat the revision directory: %s", revisionDir)
+	}
+	return pairs, nil
+}
+
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiyaml "k8s.io/apimachinery/pkg/util/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+func TestDiffSetRun(t *testing.T) {
+	dir := t.TempDir()
+	breakingPath := writeModifiedCRD(t, dir, "breaking.yaml", "testdata/base.yaml", func(r *v1.CustomResourceDefinition) {
+		removeSpecForProviderProperty(r, 0, "tags")
+	})
+	nonBreakingPath := writeModifiedCRD(t, dir, "nonbreaking.yaml", "testdata/base.yaml", func(r *v1.CustomResourceDefinition) {
+		addSpecForProviderProperty(r, 0, "optionalField", v1.JSONSchemaProps{Type: "string"}, nil)
+	})
+
+	type want struct {
+		names    []string
+		breaking []bool
+		changed  []bool
+		errs     []bool
+	}
+	tests := map[string]struct {
+		reason string
+		pairs  []DiffPair
+		opts   []DiffSetOption
+		want   want
+	}{
+		"Empty": {
+			reason: "An empty set should produce no results",
+			want:   want{names: []string{}, breaking: []bool{}, changed: []bool{}, errs: []bool{}},
+		},
+		"OrderPreservedWithErrors": {
+			reason: "Results should be in input order and a failing pair should not abort the others",
+			pairs: []DiffPair{
+				{Name: "z-breaking", BasePath: "testdata/base.yaml", RevisionPath: breakingPath},
+				{Name: "a-missing", BasePath: "testdata/base.yaml", RevisionPath: "non-existent"},
+				{Name: "m-same", BasePath: "testdata/base.yaml", RevisionPath: "testdata/base.yaml"},
+				{Name: "b-nonbreaking", BasePath: "testdata/base.yaml", RevisionPath: nonBreakingPath},
+			},
+			opts: []DiffSetOption{WithDiffSetParallelism(3)},
+			want: want{
+				names:    []string{"z-breaking", "a-missing", "m-same", "b-nonbreaking"},
+				breaking: []bool{true, false, false, false},
+				changed:  []bool{true, false, false, false},
+				errs:     []bool{false, true, false, false},
+			},
+		},
+		"KeepAllChanges": {
+			reason: "Non-breaking changes should be kept in the diffs if requested",
+			pairs: []DiffPair{
+				{Name: "b-nonbreaking", BasePath: "testdata/base.yaml", RevisionPath: nonBreakingPath},
+				{Name: "z-breaking", BasePath: "testdata/base.yaml", RevisionPath: breakingPath},
+			},
+			opts: []DiffSetOption{WithDiffSetParallelism(1), WithDiffSetKeepAllChanges(true)},
+			want: want{
+				names:    []string{"b-nonbreaking", "z-breaking"},
+				breaking: []bool{false, true},
+				changed:  []bool{true, true},
+				errs:     []bool{false, false},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := NewDiffSet(tt.pairs, tt.opts...).Run(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nRun(...): unexpected error: %v", tt.reason, err)
+			}
+			got := want{names: []string{}, breaking: []bool{}, changed: []bool{}, errs: []bool{}}
+			for _, r := range results {
+				got.names = append(got.names, r.Pair.Name)
+				got.breaking = append(got.breaking, r.HasBreakingChanges)
+				got.changed = append(got.changed, !emptyDiffMap(r.Diff))
+				got.errs = append(got.errs, r.Err != nil)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiffSetRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pairs := []DiffPair{
+		{Name: "a", BasePath: "testdata/base.yaml", RevisionPath: "testdata/base.yaml"},
+		{Name: "b", BasePath: "testdata/base.yaml", RevisionPath: "testdata/base.yaml"},
+	}
+	results, err := NewDiffSet(pairs, WithDiffSetParallelism(1)).Run(ctx)
+	if err == nil {
+		t.Fatal("Run(...): expected an error for a canceled context")
+	}
+	if len(results) != len(pairs) {
+		t.Fatalf("Run(...): got %d results, want %d", len(results), len(pairs))
+	}
+	for i, r := range results {
+		if r.Pair.Name != pairs[i].Name {
+			t.Errorf("Run(...): result %d: got pair %q, want %q", i, r.Pair.Name, pairs[i].Name)
+		}
+		if r.Err == nil {
+			t.Errorf("Run(...): result %d: expected the context error", i)
+		}
+	}
+}
+
+func TestDiffPairsFromDirs(t *testing.T) {
+	base, revision := t.TempDir(), t.TempDir()
+	for _, f := range []string{"b.yaml", "a.yml", "sub/c.yaml", "README.md"} {
+		writeFile(t, filepath.Join(base, f))
+	}
+	writeFile(t, filepath.Join(revision, "new.yaml"))
+
+	got, err := DiffPairsFromDirs(base, revision)
+	if err != nil {
+		t.Fatalf("DiffPairsFromDirs(...): unexpected error: %v", err)
+	}
+	want := []DiffPair{
+		{Name: "a.yml", BasePath: filepath.Join(base, "a.yml"), RevisionPath: filepath.Join(revision, "a.yml")},
+		{Name: "b.yaml", BasePath: filepath.Join(base, "b.yaml"), RevisionPath: filepath.Join(revision, "b.yaml")},
+		{Name: "sub/c.yaml", BasePath: filepath.Join(base, "sub", "c.yaml"), RevisionPath: filepath.Join(revision, "sub", "c.yaml")},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiffPairsFromDirs(...): -want, +got:\n%s", diff)
+	}
+
+	if _, err := DiffPairsFromDirs(base, filepath.Join(revision, "non-existent")); err == nil {
+		t.Error("DiffPairsFromDirs(...): expected an error for a non-existent revision directory")
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeModifiedCRD(t *testing.T, dir, name, basePath string, modifiers ...crdModifier) string {
+	t.Helper()
+	buff, err := os.ReadFile(filepath.Clean(basePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crd := &v1.CustomResourceDefinition{}
+	if err := apiyaml.Unmarshal(buff, crd); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range modifiers {
+		m(crd)
+	}
+	buff, err = k8syaml.Marshal(crd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, buff, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
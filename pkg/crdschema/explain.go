@@ -0,0 +1,88 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	kinoapi "github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ChangeExplanation is the before and after view of a changed field.
+type ChangeExplanation struct {
+	// BaseSchema is the YAML representation of the field's subschema
+	// in the base. Empty if the field has been added.
+	BaseSchema string `json:"baseSchema,omitempty"`
+
+	// RevisionSchema is the YAML representation of the field's subschema
+	// in the revision. Empty if the field has been deleted.
+	RevisionSchema string `json:"revisionSchema,omitempty"`
+
+	// BaseDescription is the description of the field in the base.
+	BaseDescription string `json:"baseDescription,omitempty"`
+
+	// RevisionDescription is the description of the field in
+	// the revision.
+	RevisionDescription string `json:"revisionDescription,omitempty"`
+}
+
+// Explain returns the explanation of the change from its base and
+// revision subschemas.
+func (c *SchemaChange) Explain() (*ChangeExplanation, error) {
+	e := &ChangeExplanation{}
+	var err error
+	if e.BaseSchema, err = schemaYAML(c.BaseSchema); err != nil {
+		return nil, errors.Wrapf(err, "failed to explain the change at path: %s", c.Path)
+	}
+	if e.RevisionSchema, err = schemaYAML(c.RevisionSchema); err != nil {
+		return nil, errors.Wrapf(err, "failed to explain the change at path: %s", c.Path)
+	}
+	if c.BaseSchema != nil {
+		e.BaseDescription = c.BaseSchema.Description
+	}
+	if c.RevisionSchema != nil {
+		e.RevisionDescription = c.RevisionSchema.Description
+	}
+	return e, nil
+}
+
+// Explain sets the explanations of all the changes in the report.
+// The report's fingerprint is not affected.
+func (r *ChangeReport) Explain() error {
+	if r == nil {
+		return nil
+	}
+	for _, vc := range r.Versions {
+		if vc == nil {
+			continue
+		}
+		for i := range vc.Changes {
+			e, err := vc.Changes[i].Explain()
+			if err != nil {
+				return err
+			}
+			vc.Changes[i].Explanation = e
+		}
+	}
+	return nil
+}
+
+func schemaYAML(s *kinoapi.Schema) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	buff, err := yaml.Marshal(s)
+	return string(buff), errors.Wrap(err, "failed to marshal the schema as YAML")
+}
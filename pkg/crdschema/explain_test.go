@@ -0,0 +1,81 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestChangeReportExplain(t *testing.T) {
+	d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil,
+		func(r *v1.CustomResourceDefinition) {
+			removeSpecForProviderProperty(r, 0, "tags")
+			addSpecForProviderProperty(r, 0, "newField", v1.JSONSchemaProps{Type: "string", Description: "A brand new field"}, nil)
+			p := getSpecForProviderProperty(r, 0, "domainName")
+			p.Type = "integer"
+			addSpecForProviderProperty(r, 0, "domainName", p, nil)
+		})
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	rawDiff, err := d.GetRawDiff()
+	if err != nil {
+		t.Fatalf("GetRawDiff(): error = %v", err)
+	}
+	report, err := GetChangesAsStructured(rawDiff, true)
+	if err != nil {
+		t.Fatalf("GetChangesAsStructured(...): error = %v", err)
+	}
+	fingerprint := report.Fingerprint
+	if err := report.Explain(); err != nil {
+		t.Fatalf("Explain(): error = %v", err)
+	}
+
+	want := map[string]*ChangeExplanation{
+		"spec.forProvider.domainName": {
+			BaseSchema:          "description: A domain name for which the certificate should be issued\ntype: string\n",
+			RevisionSchema:      "description: A domain name for which the certificate should be issued\ntype: integer\n",
+			BaseDescription:     "A domain name for which the certificate should be issued",
+			RevisionDescription: "A domain name for which the certificate should be issued",
+		},
+		"spec.forProvider.newField": {
+			RevisionSchema:      "description: A brand new field\ntype: string\n",
+			RevisionDescription: "A brand new field",
+		},
+		"spec.forProvider.tags": {
+			BaseSchema:      "additionalProperties:\n  type: string\ndescription: Key-value map of resource tags.\ntype: object\n",
+			BaseDescription: "Key-value map of resource tags.",
+		},
+	}
+	got := make(map[string]*ChangeExplanation)
+	for _, c := range report.Versions["v1beta1"].Changes {
+		got[c.Path] = c.Explanation
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Explain(): -want, +got:\n%s", diff)
+	}
+
+	// explanations are not part of the fingerprint
+	explained, err := report.ComputeFingerprint()
+	if err != nil {
+		t.Fatalf("ComputeFingerprint(): error = %v", err)
+	}
+	if explained != fingerprint {
+		t.Errorf("ComputeFingerprint(): got %q after explaining the changes, want %q", explained, fingerprint)
+	}
+}
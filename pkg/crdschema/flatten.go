@@ -15,6 +15,9 @@
 package crdschema
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	kinoapi "github.com/getkin/kin-openapi/openapi3"
@@ -35,7 +38,20 @@ func FlattenDiff(d *diff.Diff) []SchemaChange {
 	}
 
 	// Recursively walk the schema diff tree and extract all changes
-	return walkSchemaDiff("", sd)
+	changes := walkSchemaDiff(fieldPath{}, sd)
+	sortChanges(changes)
+	return changes
+}
+
+// sortChanges sorts the changes by their paths and then by their change
+// types so that the flattened diff does not depend on map iteration order.
+func sortChanges(changes []SchemaChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].ChangeType < changes[j].ChangeType
+	})
 }
 
 // extractSchemaDiff navigates the oasdiff structure to find the SchemaDiff
@@ -64,7 +80,7 @@ func extractSchemaDiff(d *diff.Diff) *diff.SchemaDiff {
 }
 
 // walkSchemaDiff recursively traverses a SchemaDiff tree and extracts all changes
-func walkSchemaDiff(path string, sd *diff.SchemaDiff) []SchemaChange {
+func walkSchemaDiff(path fieldPath, sd *diff.SchemaDiff) []SchemaChange {
 	if sd == nil {
 		return nil
 	}
@@ -74,20 +90,22 @@ func walkSchemaDiff(path string, sd *diff.SchemaDiff) []SchemaChange {
 	// Handle schema lifecycle (added/deleted)
 	if sd.SchemaAdded {
 		changes = append(changes, SchemaChange{
-			Path:          path,
-			PathParts:     parsePath(path),
-			ChangeType:    ChangeTypeFieldAdded,
-			RawSchemaDiff: sd,
+			Path:           path.String(),
+			PathParts:      path.Parts(),
+			ChangeType:     ChangeTypeFieldAdded,
+			RawSchemaDiff:  sd,
+			RevisionSchema: sd.Revision,
 		})
 		return changes // Don't process further if entire schema added
 	}
 
 	if sd.SchemaDeleted {
 		changes = append(changes, SchemaChange{
-			Path:          path,
-			PathParts:     parsePath(path),
+			Path:          path.String(),
+			PathParts:     path.Parts(),
 			ChangeType:    ChangeTypeFieldDeleted,
 			RawSchemaDiff: sd,
+			BaseSchema:    sd.Base,
 		})
 		return changes // Don't process further if entire schema deleted
 	}
@@ -96,12 +114,17 @@ func walkSchemaDiff(path string, sd *diff.SchemaDiff) []SchemaChange {
 	changes = append(changes, extractPropertyChanges(path, sd)...)
 	changes = append(changes, extractTypeChanges(path, sd)...)
 	changes = append(changes, extractItemsChanges(path, sd)...)
+	changes = append(changes, extractAdditionalPropertiesChanges(path, sd)...)
+	changes = append(changes, extractSubschemasChanges(path, "allOf", sd, sd.AllOfDiff)...)
+	changes = append(changes, extractSubschemasChanges(path, "oneOf", sd, sd.OneOfDiff)...)
+	changes = append(changes, extractSubschemasChanges(path, "anyOf", sd, sd.AnyOfDiff)...)
+	changes = append(changes, extractNotChanges(path, sd)...)
 
 	return changes
 }
 
 // extractPropertyChanges handles changes to object properties (PropertiesDiff)
-func extractPropertyChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
+func extractPropertyChanges(path fieldPath, sd *diff.SchemaDiff) []SchemaChange {
 	if sd.PropertiesDiff == nil {
 		return nil
 	}
@@ -117,37 +140,44 @@ func extractPropertyChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
 		if shouldSkipDueToArrayObjectConversion(sd) {
 			continue
 		}
-		propPath := joinPath(path, propName)
+		propPath := path.child(propName)
 		changes = append(changes, SchemaChange{
-			Path:          propPath,
-			PathParts:     parsePath(propPath),
-			ChangeType:    ChangeTypeFieldAdded,
-			RawSchemaDiff: sd,
+			Path:           propPath.String(),
+			PathParts:      propPath.Parts(),
+			ChangeType:     ChangeTypeFieldAdded,
+			RawSchemaDiff:  sd,
+			RevisionSchema: property(sd.Revision, propName),
 		})
 	}
 
 	// Deleted properties
 	for _, propName := range sd.PropertiesDiff.Deleted {
-		propPath := joinPath(path, propName)
+		propPath := path.child(propName)
 		changes = append(changes, SchemaChange{
-			Path:          propPath,
-			PathParts:     parsePath(propPath),
+			Path:          propPath.String(),
+			PathParts:     propPath.Parts(),
 			ChangeType:    ChangeTypeFieldDeleted,
 			RawSchemaDiff: sd,
+			BaseSchema:    property(sd.Base, propName),
 		})
 	}
 
 	// Modified properties - recurse into nested changes
-	for propName, propDiff := range sd.PropertiesDiff.Modified {
-		propPath := joinPath(path, propName)
-		changes = append(changes, walkSchemaDiff(propPath, propDiff)...)
+	propNames := make([]string, 0, len(sd.PropertiesDiff.Modified))
+	for propName := range sd.PropertiesDiff.Modified {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	for _, propName := range propNames {
+		propPath := path.child(propName)
+		changes = append(changes, walkSchemaDiff(propPath, sd.PropertiesDiff.Modified[propName])...)
 	}
 
 	return changes
 }
 
 // extractTypeChanges handles type and format changes
-func extractTypeChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
+func extractTypeChanges(path fieldPath, sd *diff.SchemaDiff) []SchemaChange {
 	// Pre-allocate: maximum 2 changes (type + format)
 	changes := make([]SchemaChange, 0, 2)
 
@@ -160,14 +190,16 @@ func extractTypeChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
 	// Type change (e.g., string → integer)
 	if sd.TypeDiff != nil && !sd.TypeDiff.Empty() {
 		schChange := SchemaChange{
-			Path:       path,
-			PathParts:  parsePath(path),
+			Path:       path.String(),
+			PathParts:  path.Parts(),
 			ChangeType: ChangeTypeTypeChanged,
 			TypeChangeDetails: &TypeChangeDetails{
 				Added:   sd.TypeDiff.Added,
 				Deleted: sd.TypeDiff.Deleted,
 			},
-			RawSchemaDiff: sd,
+			RawSchemaDiff:  sd,
+			BaseSchema:     sd.Base,
+			RevisionSchema: sd.Revision,
 		}
 		// set original types for display purposes
 		if sd.Base != nil {
@@ -184,20 +216,74 @@ func extractTypeChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
 
 // Helper functions
 
-// joinPath concatenates path segments with dot notation
-func joinPath(base, part string) string {
-	if base == "" {
-		return part
+// fieldPath is the path of a schema node being walked. The path components
+// are accumulated during the walk instead of being split from the rendered
+// path, so that property names containing separators, such as
+// "example.com/key", are kept intact in SchemaChange.PathParts.
+type fieldPath struct {
+	// parts are the property names along the path. Markers for the array
+	// items, map values and subschemas are appended to the last part,
+	// e.g., "validationOption[*]".
+	parts []string
+	// rendered is the JSONPath representation of the path.
+	rendered string
+}
+
+// child returns the path of the property with the specified name.
+func (p fieldPath) child(name string) fieldPath {
+	parts := make([]string, len(p.parts), len(p.parts)+1)
+	copy(parts, p.parts)
+	return fieldPath{
+		parts:    append(parts, name),
+		rendered: joinPath(p.rendered, name),
 	}
-	return base + "." + part
 }
 
-// parsePath splits a dot-notation path into components
-func parsePath(path string) []string {
-	if path == "" {
-		return []string{}
+// withMarker returns the path with the specified marker, such as "[*]",
+// appended to its last component.
+func (p fieldPath) withMarker(marker string) fieldPath {
+	parts := make([]string, len(p.parts))
+	copy(parts, p.parts)
+	if len(parts) == 0 {
+		parts = append(parts, marker)
+	} else {
+		parts[len(parts)-1] += marker
+	}
+	return fieldPath{
+		parts:    parts,
+		rendered: p.rendered + marker,
 	}
-	return strings.Split(path, ".")
+}
+
+// String returns the JSONPath representation of the path.
+func (p fieldPath) String() string {
+	return p.rendered
+}
+
+// Parts returns the components of the path.
+func (p fieldPath) Parts() []string {
+	parts := make([]string, len(p.parts))
+	copy(parts, p.parts)
+	return parts
+}
+
+// regexPathIdentifier matches the property names that can be represented
+// in a JSONPath using the dot notation.
+var regexPathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// joinPath appends a property name to a JSONPath. Property names that are
+// not plain identifiers, e.g., "example.com/key", are appended using the
+// bracket notation with the quotes and the backslashes escaped,
+// e.g., "metadata.labels['example.com/key']".
+func joinPath(base, name string) string {
+	if !regexPathIdentifier.MatchString(name) {
+		r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		return base + "['" + r.Replace(name) + "']"
+	}
+	if base == "" {
+		return name
+	}
+	return base + "." + name
 }
 
 // shouldSkipDueToArrayObjectConversion checks if we should skip reporting changes
@@ -216,7 +302,7 @@ func shouldSkipDueToArrayObjectConversion(sd *diff.SchemaDiff) bool {
 // extractItemsChanges handles changes to array item schemas (ItemsDiff).
 // This captures changes like adding/removing fields in array item objects,
 // or changing the type of array items.
-func extractItemsChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
+func extractItemsChanges(path fieldPath, sd *diff.SchemaDiff) []SchemaChange {
 	if sd.ItemsDiff == nil {
 		return nil
 	}
@@ -237,6 +323,118 @@ func extractItemsChanges(path string, sd *diff.SchemaDiff) []SchemaChange {
 
 	// Recursively process changes to the array item schema.
 	// Use [*] notation to indicate this is the schema for array items.
-	itemPath := path + "[*]"
+	itemPath := path.withMarker("[*]")
 	return walkSchemaDiff(itemPath, sd.ItemsDiff)
 }
+
+// extractAdditionalPropertiesChanges handles changes to the schemas of
+// map values (AdditionalPropertiesDiff), e.g., the value type of a
+// map[string]string field changing.
+func extractAdditionalPropertiesChanges(path fieldPath, sd *diff.SchemaDiff) []SchemaChange {
+	if sd.AdditionalPropertiesDiff == nil {
+		return nil
+	}
+
+	// Similar to the array items, if the field's type is changing FROM or
+	// TO object, the type change is the real change and the addition or
+	// the removal of the map value schema is a side effect.
+	if sd.TypeDiff != nil && !sd.TypeDiff.Empty() {
+		if sd.TypeDiff.Added.Is(kinoapi.TypeObject) || sd.TypeDiff.Deleted.Is(kinoapi.TypeObject) {
+			return nil
+		}
+	}
+
+	// Use {*} notation to indicate this is the schema for map values.
+	return walkSchemaDiff(path.withMarker("{*}"), sd.AdditionalPropertiesDiff)
+}
+
+// extractSubschemasChanges handles changes to the allOf, oneOf or anyOf
+// branches of a schema. The branches are identified by their zero-based
+// indices using the [<keyword>:<index>] notation, e.g., [oneOf:1].
+// Modified branches are identified by their indices in the revision.
+func extractSubschemasChanges(path fieldPath, keyword string, sd *diff.SchemaDiff, ssd *diff.SubschemasDiff) []SchemaChange {
+	if ssd.Empty() {
+		return nil
+	}
+
+	var changes []SchemaChange //nolint:prealloc // Cannot pre-allocate: size depends on recursive walkSchemaDiff calls
+
+	for _, s := range ssd.Added {
+		p := subschemaPath(path, keyword, s.Index)
+		changes = append(changes, SchemaChange{
+			Path:           p.String(),
+			PathParts:      p.Parts(),
+			ChangeType:     ChangeTypeFieldAdded,
+			RawSchemaDiff:  sd,
+			RevisionSchema: subschema(sd.Revision, keyword, s.Index),
+		})
+	}
+
+	for _, s := range ssd.Deleted {
+		p := subschemaPath(path, keyword, s.Index)
+		changes = append(changes, SchemaChange{
+			Path:          p.String(),
+			PathParts:     p.Parts(),
+			ChangeType:    ChangeTypeFieldDeleted,
+			RawSchemaDiff: sd,
+			BaseSchema:    subschema(sd.Base, keyword, s.Index),
+		})
+	}
+
+	for _, m := range ssd.Modified {
+		if m == nil {
+			continue
+		}
+		changes = append(changes, walkSchemaDiff(subschemaPath(path, keyword, m.Revision.Index), m.Diff)...)
+	}
+
+	return changes
+}
+
+// subschemaPath returns the path of the allOf, oneOf or anyOf branch
+// with the specified index.
+func subschemaPath(path fieldPath, keyword string, index int) fieldPath {
+	return path.withMarker(subschemaMarker(keyword, index))
+}
+
+// subschemaMarker returns the path marker of the allOf, oneOf or anyOf
+// branch with the specified index, e.g., [oneOf:1].
+func subschemaMarker(keyword string, index int) string {
+	return fmt.Sprintf("[%s:%d]", keyword, index)
+}
+
+// subschemas returns the allOf, oneOf or anyOf branches of the specified
+// schema.
+func subschemas(s *kinoapi.Schema, keyword string) kinoapi.SchemaRefs {
+	if s == nil {
+		return nil
+	}
+	switch keyword {
+	case "allOf":
+		return s.AllOf
+	case "oneOf":
+		return s.OneOf
+	case "anyOf":
+		return s.AnyOf
+	}
+	return nil
+}
+
+// subschema returns the allOf, oneOf or anyOf branch of the specified
+// schema with the specified index, or nil if there's no such branch.
+func subschema(s *kinoapi.Schema, keyword string, index int) *kinoapi.Schema {
+	refs := subschemas(s, keyword)
+	if index < 0 || index >= len(refs) || refs[index] == nil {
+		return nil
+	}
+	return refs[index].Value
+}
+
+// extractNotChanges handles changes to the schema under the "not" keyword
+// using the [not] notation.
+func extractNotChanges(path fieldPath, sd *diff.SchemaDiff) []SchemaChange {
+	if sd.NotDiff == nil {
+		return nil
+	}
+	return walkSchemaDiff(path.withMarker("[not]"), sd.NotDiff)
+}
@@ -334,6 +334,130 @@ func TestFlattenDiff_RevisionDiff(t *testing.T) {
 				},
 			},
 		},
+		"ChangeMapValueType": {
+			reason: "Changing the type of map values should be detected using the {*} notation",
+			args: args{
+				basePath: "testdata/base.yaml",
+				revisionModifiers: []crdModifier{
+					func(r *v1.CustomResourceDefinition) {
+						p := getSpecForProviderProperty(r, 0, "tags")
+						p.AdditionalProperties = &v1.JSONSchemaPropsOrBool{
+							Allows: true,
+							Schema: &v1.JSONSchemaProps{Type: "integer"},
+						}
+						addSpecForProviderProperty(r, 0, "tags", p, nil)
+					},
+				},
+			},
+			want: want{
+				changes: []SchemaChange{
+					{
+						Path:       "spec.forProvider.tags{*}",
+						PathParts:  []string{"spec", "forProvider", "tags{*}"},
+						ChangeType: ChangeTypeTypeChanged,
+						TypeChangeDetails: &TypeChangeDetails{
+							OldType: &kinoapi.Types{"string"},
+							NewType: &kinoapi.Types{"integer"},
+							Added:   utils.StringList{"integer"},
+							Deleted: utils.StringList{"string"},
+						},
+					},
+				},
+			},
+		},
+		"AddOneOfBranches": {
+			reason: "Adding oneOf branches should be detected using the [oneOf:<index>] notation",
+			args: args{
+				basePath: "testdata/base.yaml",
+				revisionModifiers: []crdModifier{
+					func(r *v1.CustomResourceDefinition) {
+						p := getSpecForProviderProperty(r, 0, "validationMethod")
+						p.OneOf = []v1.JSONSchemaProps{{Pattern: "^DNS$"}, {Pattern: "^EMAIL$"}}
+						addSpecForProviderProperty(r, 0, "validationMethod", p, nil)
+					},
+				},
+			},
+			want: want{
+				changes: []SchemaChange{
+					{
+						Path:       "spec.forProvider.validationMethod[oneOf:0]",
+						PathParts:  []string{"spec", "forProvider", "validationMethod[oneOf:0]"},
+						ChangeType: ChangeTypeFieldAdded,
+					},
+					{
+						Path:       "spec.forProvider.validationMethod[oneOf:1]",
+						PathParts:  []string{"spec", "forProvider", "validationMethod[oneOf:1]"},
+						ChangeType: ChangeTypeFieldAdded,
+					},
+				},
+			},
+		},
+		"AddAllOfBranch": {
+			reason: "Adding an allOf branch should be detected using the [allOf:<index>] notation",
+			args: args{
+				basePath: "testdata/base.yaml",
+				revisionModifiers: []crdModifier{
+					func(r *v1.CustomResourceDefinition) {
+						p := getSpecForProviderProperty(r, 0, "validationMethod")
+						p.AllOf = []v1.JSONSchemaProps{{Type: "integer"}}
+						addSpecForProviderProperty(r, 0, "validationMethod", p, nil)
+					},
+				},
+			},
+			want: want{
+				changes: []SchemaChange{
+					{
+						Path:       "spec.forProvider.validationMethod[allOf:0]",
+						PathParts:  []string{"spec", "forProvider", "validationMethod[allOf:0]"},
+						ChangeType: ChangeTypeFieldAdded,
+					},
+				},
+			},
+		},
+		"AddNotSchema": {
+			reason: "Adding a not schema should be detected using the [not] notation",
+			args: args{
+				basePath: "testdata/base.yaml",
+				revisionModifiers: []crdModifier{
+					func(r *v1.CustomResourceDefinition) {
+						p := getSpecForProviderProperty(r, 0, "validationMethod")
+						p.Not = &v1.JSONSchemaProps{Pattern: "^NONE$"}
+						addSpecForProviderProperty(r, 0, "validationMethod", p, nil)
+					},
+				},
+			},
+			want: want{
+				changes: []SchemaChange{
+					{
+						Path:       "spec.forProvider.validationMethod[not]",
+						PathParts:  []string{"spec", "forProvider", "validationMethod[not]"},
+						ChangeType: ChangeTypeFieldAdded,
+					},
+				},
+			},
+		},
+		"AddFieldWithDotsInName": {
+			reason: "A field name containing dots should be escaped in the path and kept intact in the path parts",
+			args: args{
+				basePath: "testdata/base.yaml",
+				revisionModifiers: []crdModifier{
+					func(r *v1.CustomResourceDefinition) {
+						p := getSpecForProviderProperty(r, 0, "validationOption")
+						p.Items.Schema.Properties["example.com/key"] = v1.JSONSchemaProps{Type: "string"}
+						addSpecForProviderProperty(r, 0, "validationOption", p, nil)
+					},
+				},
+			},
+			want: want{
+				changes: []SchemaChange{
+					{
+						Path:       "spec.forProvider.validationOption[*]['example.com/key']",
+						PathParts:  []string{"spec", "forProvider", "validationOption[*]", "example.com/key"},
+						ChangeType: ChangeTypeFieldAdded,
+					},
+				},
+			},
+		},
 	}
 
 	for name, tt := range tests {
@@ -369,8 +493,8 @@ func TestFlattenDiff_RevisionDiff(t *testing.T) {
 				return a.ChangeType < b.ChangeType
 			})
 
-			// Ignore RawSchemaDiff in comparison
-			ignoreRaw := cmpopts.IgnoreFields(SchemaChange{}, "RawSchemaDiff")
+			// Ignore the raw diff and the subschemas in comparison
+			ignoreRaw := cmpopts.IgnoreFields(SchemaChange{}, "RawSchemaDiff", "BaseSchema", "RevisionSchema")
 
 			if diff := cmp.Diff(tt.want.changes, got, sortChanges, ignoreRaw); diff != "" {
 				t.Errorf("\n%s\nGetChangesAsStructured(): -want, +got:\n%s", tt.reason, diff)
@@ -497,7 +621,7 @@ func TestFlattenDiff_SelfDiff(t *testing.T) {
 				return a.ChangeType < b.ChangeType
 			})
 
-			ignoreRaw := cmpopts.IgnoreFields(SchemaChange{}, "RawSchemaDiff")
+			ignoreRaw := cmpopts.IgnoreFields(SchemaChange{}, "RawSchemaDiff", "BaseSchema", "RevisionSchema")
 
 			if diff := cmp.Diff(tt.want.changes, got, sortChanges, ignoreRaw); diff != "" {
 				t.Errorf("\n%s\nGetChangesAsStructured(): -want, +got:\n%s", tt.reason, diff)
@@ -716,3 +840,187 @@ func TestGetChangesAsStructured_KeepAllChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestFlattenDiff_DeterministicOrder(t *testing.T) {
+	d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil,
+		func(r *v1.CustomResourceDefinition) {
+			removeSpecForProviderProperty(r, 0, "tags")
+			removeSpecForProviderProperty(r, 0, "certificateAuthorityArn")
+			addSpecForProviderProperty(r, 0, "aNewField", v1.JSONSchemaProps{Type: "string"}, nil)
+			p := getSpecForProviderProperty(r, 0, "domainName")
+			p.Type = "integer"
+			addSpecForProviderProperty(r, 0, "domainName", p, nil)
+		})
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	want := []string{
+		"spec.forProvider.aNewField:field_added",
+		"spec.forProvider.certificateAuthorityArn:field_deleted",
+		"spec.forProvider.domainName:type_changed",
+		"spec.forProvider.tags:field_deleted",
+	}
+	// run multiple times as the map iteration order is randomized
+	for range 10 {
+		rawDiff, err := d.GetRawDiff()
+		if err != nil {
+			t.Fatalf("GetRawDiff(): error = %v", err)
+		}
+		got := make([]string, 0, len(want))
+		for _, c := range FlattenDiff(rawDiff["v1beta1"]) {
+			got = append(got, c.Path+":"+string(c.ChangeType))
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("FlattenDiff(...): -want, +got:\n%s", diff)
+		}
+	}
+}
+
+func TestChangeReport_ComputeFingerprint(t *testing.T) {
+	newReport := func(paths ...string) *ChangeReport {
+		r := &ChangeReport{Versions: map[string]*VersionChanges{}}
+		for _, p := range paths {
+			r.Versions[p] = &VersionChanges{
+				NewVersion: p,
+				Changes:    []SchemaChange{{Path: p, PathParts: []string{p}, ChangeType: ChangeTypeFieldDeleted}},
+			}
+		}
+		return r
+	}
+	tests := map[string]struct {
+		reason string
+		a, b   *ChangeReport
+		equal  bool
+	}{
+		"NilAndEmpty": {
+			reason: "A nil report and a report without versions should have the same fingerprint",
+			a:      nil,
+			b:      &ChangeReport{},
+			equal:  true,
+		},
+		"NilAndEmptyVersions": {
+			reason: "A report with nil versions and a report with an empty map of versions should have the same fingerprint",
+			a:      &ChangeReport{},
+			b:      newReport(),
+			equal:  true,
+		},
+		"SameChanges": {
+			reason: "Reports with the same changes should have the same fingerprint",
+			a:      newReport("v1", "v2"),
+			b:      newReport("v2", "v1"),
+			equal:  true,
+		},
+		"IgnoresFingerprintField": {
+			reason: "The report's own fingerprint should not affect the digest",
+			a:      newReport("v1"),
+			b: func() *ChangeReport {
+				r := newReport("v1")
+				r.Fingerprint = "stale"
+				return r
+			}(),
+			equal: true,
+		},
+		"DifferentChanges": {
+			reason: "Reports with different changes should have different fingerprints",
+			a:      newReport("v1"),
+			b:      newReport("v2"),
+			equal:  false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fa, err := tt.a.ComputeFingerprint()
+			if err != nil {
+				t.Fatalf("\n%s\nComputeFingerprint(): error = %v", tt.reason, err)
+			}
+			fb, err := tt.b.ComputeFingerprint()
+			if err != nil {
+				t.Fatalf("\n%s\nComputeFingerprint(): error = %v", tt.reason, err)
+			}
+			if (fa == fb) != tt.equal {
+				t.Errorf("\n%s\nComputeFingerprint(): got %q and %q, want equal = %v", tt.reason, fa, fb, tt.equal)
+			}
+		})
+	}
+}
+
+func TestFlattenDiff_ModifiedSubschema(t *testing.T) {
+	withOneOf := func(branchType string) crdModifier {
+		return func(r *v1.CustomResourceDefinition) {
+			p := getSpecForProviderProperty(r, 0, "validationMethod")
+			p.OneOf = []v1.JSONSchemaProps{{Title: "other", Pattern: "^EMAIL$"}, {Title: "dns", Type: branchType}}
+			addSpecForProviderProperty(r, 0, "validationMethod", p, nil)
+		}
+	}
+	basePath := writeModifiedCRD(t, t.TempDir(), "base.yaml", "testdata/base.yaml", withOneOf("string"))
+	d, err := newRevisionDiffWithModifiers(basePath, basePath, nil, withOneOf("integer"))
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	rawDiff, err := d.GetRawDiff()
+	if err != nil {
+		t.Fatalf("GetRawDiff(): error = %v", err)
+	}
+	want := []SchemaChange{
+		{
+			Path:       "spec.forProvider.validationMethod[oneOf:1]",
+			PathParts:  []string{"spec", "forProvider", "validationMethod[oneOf:1]"},
+			ChangeType: ChangeTypeTypeChanged,
+			TypeChangeDetails: &TypeChangeDetails{
+				OldType: &kinoapi.Types{"string"},
+				NewType: &kinoapi.Types{"integer"},
+				Added:   utils.StringList{"integer"},
+				Deleted: utils.StringList{"string"},
+			},
+		},
+	}
+	ignoreRaw := cmpopts.IgnoreFields(SchemaChange{}, "RawSchemaDiff", "BaseSchema", "RevisionSchema")
+	if diff := cmp.Diff(want, FlattenDiff(rawDiff["v1beta1"]), ignoreRaw); diff != "" {
+		t.Errorf("FlattenDiff(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := map[string]struct {
+		reason string
+		base   string
+		name   string
+		want   string
+	}{
+		"Root": {
+			reason: "A plain identifier at the root should not be prefixed",
+			name:   "spec",
+			want:   "spec",
+		},
+		"Identifier": {
+			reason: "A plain identifier should be appended using the dot notation",
+			base:   "spec",
+			name:   "forProvider",
+			want:   "spec.forProvider",
+		},
+		"Dots": {
+			reason: "A name with dots should be appended using the bracket notation",
+			base:   "spec.tags",
+			name:   "example.com/key",
+			want:   "spec.tags['example.com/key']",
+		},
+		"RootDots": {
+			reason: "A name with dots at the root should be appended using the bracket notation",
+			name:   "example.com/key",
+			want:   "['example.com/key']",
+		},
+		"Quotes": {
+			reason: "Quotes and backslashes in a name should be escaped",
+			base:   "spec",
+			name:   `it's\here`,
+			want:   `spec['it\'s\\here']`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := joinPath(tt.base, tt.name); got != tt.want {
+				t.Errorf("\n%s\njoinPath(%q, %q): got %q, want %q", tt.reason, tt.base, tt.name, got, tt.want)
+			}
+		})
+	}
+}
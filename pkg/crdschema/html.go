@@ -0,0 +1,298 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	_ "embed" // for embedding the HTML report template
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	kinoapi "github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/oasdiff/diff"
+	"github.com/pkg/errors"
+)
+
+// Node statuses in the HTML schema tree
+const (
+	nodeStatusAdded     = "added"
+	nodeStatusRemoved   = "removed"
+	nodeStatusChanged   = "changed"
+	nodeStatusModified  = "modified"
+	nodeStatusUnchanged = "unchanged"
+)
+
+//go:embed html.tmpl
+var htmlTemplate string
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(htmlTemplate))
+
+// HTMLSection is the set of schema changes of a single CRD to be rendered
+// in an HTML report.
+type HTMLSection struct {
+	// Name identifies the CRD in the report, e.g., its manifest path.
+	Name string
+	// Diffs maps the CRD version names to their computed diffs.
+	Diffs map[string]*diff.Diff
+	// PolicyViolations are the changes violating the policy the changes
+	// are checked against, if any.
+	PolicyViolations []PolicyViolation
+	// Err is the error encountered while comparing the CRD, if any.
+	Err error
+	// Explain configures whether the base and revision subschemas of the
+	// added, removed and changed fields are rendered.
+	Explain bool
+}
+
+// htmlSection is the view model of an HTMLSection.
+type htmlSection struct {
+	Name       string
+	Error      string
+	Versions   []*htmlVersion
+	Violations []PolicyViolation
+}
+
+// htmlVersion is the view model of the schema changes of a CRD version.
+type htmlVersion struct {
+	OldVersion string
+	NewVersion string
+	Added      int
+	Removed    int
+	Changed    int
+	Root       *htmlNode
+}
+
+// htmlNode is a node in the merged schema tree of the base and the
+// revision schemas.
+type htmlNode struct {
+	Name         string
+	Path         string
+	Status       string
+	BaseType     string
+	RevisionType string
+	Description  string
+	// BaseSchema and RevisionSchema are the YAML representations of the
+	// subschemas if the node is explained.
+	BaseSchema     string
+	RevisionSchema string
+	Children       []*htmlNode
+}
+
+// RenderHTML renders the schema changes of the specified CRDs as a
+// self-contained, static HTML page. For each CRD version, a collapsible
+// tree of the base and the revision schemas side by side is rendered with
+// the added, removed and changed fields highlighted.
+func RenderHTML(w io.Writer, title string, sections []HTMLSection) error {
+	data := struct {
+		Title    string
+		Sections []*htmlSection
+	}{
+		Title:    title,
+		Sections: make([]*htmlSection, 0, len(sections)),
+	}
+	for _, s := range sections {
+		hs, err := newHTMLSection(s)
+		if err != nil {
+			return errors.Wrapf(err, "failed to render the HTML report section: %s", s.Name)
+		}
+		data.Sections = append(data.Sections, hs)
+	}
+	return errors.Wrap(htmlReportTemplate.Execute(w, data), "failed to render the HTML report")
+}
+
+func newHTMLSection(s HTMLSection) (*htmlSection, error) {
+	hs := &htmlSection{
+		Name:       s.Name,
+		Violations: s.PolicyViolations,
+	}
+	if s.Err != nil {
+		hs.Error = s.Err.Error()
+		return hs, nil
+	}
+	for _, v := range SortedVersionNames(s.Diffs) {
+		d := s.Diffs[v]
+		if d == nil || d.Empty() {
+			continue
+		}
+		sd := extractSchemaDiff(d)
+		if sd == nil {
+			continue
+		}
+		hv := &htmlVersion{NewVersion: v}
+		if d.InfoDiff != nil && d.InfoDiff.VersionDiff != nil {
+			hv.OldVersion, _ = d.InfoDiff.VersionDiff.From.(string)
+		}
+		changes := make(map[string]ChangeType)
+		for _, c := range FlattenDiff(d) {
+			changes[c.Path] = c.ChangeType
+			switch c.ChangeType {
+			case ChangeTypeFieldAdded:
+				hv.Added++
+			case ChangeTypeFieldDeleted:
+				hv.Removed++
+			case ChangeTypeTypeChanged:
+				hv.Changed++
+			}
+		}
+		b := &htmlTreeBuilder{changes: changes, explain: s.Explain}
+		hv.Root = b.node("", fieldPath{}, sd.Base, sd.Revision)
+		if b.err != nil {
+			return nil, errors.Wrapf(b.err, "failed to render the changes of version: %s", v)
+		}
+		hs.Versions = append(hs.Versions, hv)
+	}
+	return hs, nil
+}
+
+// htmlTreeBuilder builds the merged schema trees of the base and the
+// revision schemas.
+type htmlTreeBuilder struct {
+	// changes maps the paths of the changed fields to their change types.
+	changes map[string]ChangeType
+	explain bool
+	// err is the first error encountered while building the tree.
+	err error
+}
+
+// node builds the merged schema tree rooted at the specified path
+// from the base and revision schemas, either of which can be nil.
+func (b *htmlTreeBuilder) node(name string, path fieldPath, base, revision *kinoapi.Schema) *htmlNode {
+	n := &htmlNode{
+		Name:         name,
+		Path:         path.String(),
+		BaseType:     schemaTypeString(base),
+		RevisionType: schemaTypeString(revision),
+		Status:       nodeStatusUnchanged,
+	}
+	if revision != nil {
+		n.Description = revision.Description
+	}
+	if n.Description == "" && base != nil {
+		n.Description = base.Description
+	}
+	switch b.changes[n.Path] {
+	case ChangeTypeFieldAdded:
+		n.Status = nodeStatusAdded
+	case ChangeTypeFieldDeleted:
+		n.Status = nodeStatusRemoved
+	case ChangeTypeTypeChanged:
+		n.Status = nodeStatusChanged
+	}
+	if b.explain && n.Status != nodeStatusUnchanged && b.err == nil {
+		if n.BaseSchema, b.err = schemaYAML(base); b.err == nil {
+			n.RevisionSchema, b.err = schemaYAML(revision)
+		}
+	}
+
+	n.Children = b.children(path, base, revision)
+	if n.Status == nodeStatusUnchanged {
+		for _, c := range n.Children {
+			if c.Status != nodeStatusUnchanged {
+				n.Status = nodeStatusModified
+				break
+			}
+		}
+	}
+	return n
+}
+
+// children builds the merged schema trees of the properties, the array
+// items, the map values, and the allOf, oneOf, anyOf and not subschemas
+// of the specified base and revision schemas, with the same path notation
+// as FlattenDiff.
+func (b *htmlTreeBuilder) children(path fieldPath, base, revision *kinoapi.Schema) []*htmlNode {
+	var children []*htmlNode //nolint:prealloc // Cannot pre-allocate: size depends on the subschemas
+	for _, p := range propertyNames(base, revision) {
+		children = append(children, b.node(p, path.child(p), property(base, p), property(revision, p)))
+	}
+	if bi, ri := items(base), items(revision); bi != nil || ri != nil {
+		children = append(children, b.node("[*]", path.withMarker("[*]"), bi, ri))
+	}
+	if ba, ra := additionalProperties(base), additionalProperties(revision); ba != nil || ra != nil {
+		children = append(children, b.node("{*}", path.withMarker("{*}"), ba, ra))
+	}
+	// the branches are paired by their indices
+	for _, kw := range []string{"allOf", "oneOf", "anyOf"} {
+		for i := range max(len(subschemas(base, kw)), len(subschemas(revision, kw))) {
+			m := subschemaMarker(kw, i)
+			children = append(children, b.node(m, path.withMarker(m), subschema(base, kw, i), subschema(revision, kw, i)))
+		}
+	}
+	if bn, rn := notSchema(base), notSchema(revision); bn != nil || rn != nil {
+		children = append(children, b.node("[not]", path.withMarker("[not]"), bn, rn))
+	}
+	return children
+}
+
+func schemaTypeString(s *kinoapi.Schema) string {
+	if s == nil {
+		return ""
+	}
+	var t string
+	if s.Type != nil {
+		t = strings.Join(s.Type.Slice(), "|")
+	}
+	if s.Format != "" {
+		t += " (" + s.Format + ")"
+	}
+	return t
+}
+
+func propertyNames(schemas ...*kinoapi.Schema) []string {
+	set := make(map[string]struct{})
+	for _, s := range schemas {
+		if s == nil {
+			continue
+		}
+		for p := range s.Properties {
+			set[p] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(set))
+	for p := range set {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func property(s *kinoapi.Schema, name string) *kinoapi.Schema {
+	if s == nil || s.Properties[name] == nil {
+		return nil
+	}
+	return s.Properties[name].Value
+}
+
+func items(s *kinoapi.Schema) *kinoapi.Schema {
+	if s == nil || s.Items == nil {
+		return nil
+	}
+	return s.Items.Value
+}
+
+func additionalProperties(s *kinoapi.Schema) *kinoapi.Schema {
+	if s == nil || s.AdditionalProperties.Schema == nil {
+		return nil
+	}
+	return s.AdditionalProperties.Schema.Value
+}
+
+func notSchema(s *kinoapi.Schema) *kinoapi.Schema {
+	if s == nil || s.Not == nil {
+		return nil
+	}
+	return s.Not.Value
+}
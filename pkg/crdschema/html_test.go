@@ -0,0 +1,138 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/oasdiff/oasdiff/diff"
+	"github.com/pkg/errors"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestRenderHTML(t *testing.T) {
+	d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil,
+		func(r *v1.CustomResourceDefinition) {
+			removeSpecForProviderProperty(r, 0, "tags")
+			addSpecForProviderProperty(r, 0, "newField", v1.JSONSchemaProps{Type: "string", Description: "A brand new field"}, nil)
+			p := getSpecForProviderProperty(r, 0, "domainName")
+			p.Type = "integer"
+			addSpecForProviderProperty(r, 0, "domainName", p, nil)
+		})
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	rawDiff, err := d.GetRawDiff()
+	if err != nil {
+		t.Fatalf("GetRawDiff(): error = %v", err)
+	}
+	subschemasDiff := htmlSubschemasDiff(t)
+
+	tests := map[string]struct {
+		reason   string
+		sections []HTMLSection
+		want     []string
+	}{
+		"Changes": {
+			reason:   "Added, removed and changed fields should be highlighted with their descriptions",
+			sections: []HTMLSection{{Name: "certificates.yaml", Diffs: rawDiff}},
+			want: []string{
+				"<h2>certificates.yaml</h2>",
+				"<h3>Version v1beta1</h3>",
+				"<span>1 added</span><span>1 removed</span><span>1 changed</span>",
+				`<details class="modified" open><summary><div class="row"><span class="name" title="spec.forProvider">forProvider</span>`,
+				`<div class="leaf added"><div class="row"><span class="name" title="spec.forProvider.newField">newField</span><span></span><span>string</span><span class="desc">A brand new field</span>`,
+				`<details class="removed" open><summary><div class="row"><span class="name" title="spec.forProvider.tags">tags</span><span>object</span><span></span>`,
+				`<div class="leaf changed"><div class="row"><span class="name" title="spec.forProvider.domainName">domainName</span><span>string</span><span>integer</span>`,
+			},
+		},
+		"Explain": {
+			reason:   "The base and revision subschemas of the changed fields should be rendered if enabled",
+			sections: []HTMLSection{{Name: "certificates.yaml", Diffs: rawDiff, Explain: true}},
+			want: []string{
+				`<div class="explain"><pre title="base"></pre><pre title="revision">description: A brand new field
+type: string
+</pre></div>`,
+			},
+		},
+		"Subschemas": {
+			reason:   "The changes in the map values, the oneOf branches and the not subschemas should be highlighted",
+			sections: []HTMLSection{{Name: "certificates.yaml", Diffs: subschemasDiff}},
+			want: []string{
+				`<div class="leaf changed"><div class="row"><span class="name" title="spec.forProvider.labels{*}">{*}</span><span>string</span><span>integer</span>`,
+				`<details class="modified" open><summary><div class="row"><span class="name" title="spec.forProvider.choice[oneOf:0]">[oneOf:0]</span>`,
+				`<div class="leaf added"><div class="row"><span class="name" title="spec.forProvider.choice[oneOf:0].extra">extra</span><span></span><span>string</span>`,
+				`<div class="leaf changed"><div class="row"><span class="name" title="spec.forProvider.exclusion[not]">[not]</span><span>string</span><span>integer</span>`,
+			},
+		},
+		"ErrorAndViolations": {
+			reason: "Errors and policy violations should be reported per CRD",
+			sections: []HTMLSection{
+				{Name: "a.yaml", Err: errors.New("boom")},
+				{Name: "b.yaml", PolicyViolations: []PolicyViolation{{Rule: RuleNoGARemoval, Version: "v1", Maturity: MaturityGA, Message: "GA version is removed"}}},
+			},
+			want: []string{
+				`<p class="error">boom</p>`,
+				"[kubernetes/rule-4a-ga] version v1 (ga): GA version is removed",
+				"<p>No changes.</p>",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			buff := &bytes.Buffer{}
+			if err := RenderHTML(buff, "report", tt.sections); err != nil {
+				t.Fatalf("\n%s\nRenderHTML(...): unexpected error: %v", tt.reason, err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(buff.String(), w) {
+					t.Errorf("\n%s\nRenderHTML(...): output does not contain %q", tt.reason, w)
+				}
+			}
+		})
+	}
+}
+
+// htmlSubschemasDiff returns the raw diff of a CRD whose map values,
+// oneOf branches and not subschemas are changed in the revision.
+func htmlSubschemasDiff(t *testing.T) map[string]*diff.Diff {
+	t.Helper()
+	fields := func(valueType string, choice map[string]v1.JSONSchemaProps) crdModifier {
+		return func(r *v1.CustomResourceDefinition) {
+			addSpecForProviderProperty(r, 0, "labels", v1.JSONSchemaProps{
+				Type:                 "object",
+				AdditionalProperties: &v1.JSONSchemaPropsOrBool{Allows: true, Schema: &v1.JSONSchemaProps{Type: valueType}},
+			}, nil)
+			addSpecForProviderProperty(r, 0, "choice", v1.JSONSchemaProps{
+				Type:  "object",
+				OneOf: []v1.JSONSchemaProps{{Type: "object", Properties: choice}},
+			}, nil)
+			addSpecForProviderProperty(r, 0, "exclusion", v1.JSONSchemaProps{Not: &v1.JSONSchemaProps{Type: valueType}}, nil)
+		}
+	}
+	d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil,
+		fields("integer", map[string]v1.JSONSchemaProps{"name": {Type: "string"}, "extra": {Type: "string"}}))
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	fields("string", map[string]v1.JSONSchemaProps{"name": {Type: "string"}})(d.baseCRD)
+	rawDiff, err := d.GetRawDiff()
+	if err != nil {
+		t.Fatalf("GetRawDiff(): error = %v", err)
+	}
+	return rawDiff
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/oasdiff/oasdiff/diff"
+	"github.com/pkg/errors"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// Policy is a set of rules that decide which changes between the base and
+// the revision CRDs are acceptable.
+type Policy string
+
+const (
+	// PolicyNone treats every version the same and every breaking change
+	// is a failure. Base and revision versions are paired by their order.
+	PolicyNone Policy = "none"
+	// PolicyKubernetes enforces the Kubernetes API deprecation policy,
+	// which applies different rules depending on the maturity of each
+	// version. Base and revision versions are paired by their names.
+	PolicyKubernetes Policy = "kubernetes"
+)
+
+// Maturity is the stability level of an API version.
+type Maturity string
+
+const (
+	// MaturityAlpha is the maturity of the alpha versions, e.g., v1alpha1.
+	MaturityAlpha Maturity = "alpha"
+	// MaturityBeta is the maturity of the beta versions, e.g., v1beta1.
+	MaturityBeta Maturity = "beta"
+	// MaturityGA is the maturity of the GA versions, e.g., v1.
+	MaturityGA Maturity = "ga"
+)
+
+var maturityLevels = map[Maturity]int{
+	MaturityAlpha: 0,
+	MaturityBeta:  1,
+	MaturityGA:    2,
+}
+
+var regexKubeVersion = regexp.MustCompile(`^v\d+(?:(alpha|beta)\d+)?$`)
+
+// VersionMaturity returns the maturity of the API version with the
+// specified name. Names not following the Kubernetes version conventions
+// are considered GA so that the strictest rules apply to them.
+func VersionMaturity(version string) Maturity {
+	m := regexKubeVersion.FindStringSubmatch(version)
+	if m == nil {
+		return MaturityGA
+	}
+	switch m[1] {
+	case "alpha":
+		return MaturityAlpha
+	case "beta":
+		return MaturityBeta
+	default:
+		return MaturityGA
+	}
+}
+
+// PolicyRule is a rule of a policy that can be violated by a change.
+type PolicyRule struct {
+	// ID identifies the rule.
+	ID string `json:"id"`
+	// Description is the human-readable statement of the rule.
+	Description string `json:"description"`
+}
+
+var (
+	// RuleNoRemovalWithinVersion is the Kubernetes deprecation policy's
+	// Rule #1, which applies to the beta and GA versions.
+	RuleNoRemovalWithinVersion = PolicyRule{
+		ID:          "kubernetes/rule-1",
+		Description: "API elements may only be removed by incrementing the version of the API group",
+	}
+	// RuleNoDeprecationForLessStable is the Kubernetes deprecation
+	// policy's Rule #3.
+	RuleNoDeprecationForLessStable = PolicyRule{
+		ID:          "kubernetes/rule-3",
+		Description: "An API version in a given track may not be deprecated in favor of a less stable API version",
+	}
+	// RuleDeprecateBeforeRemoval is the Kubernetes deprecation policy's
+	// Rule #4a for the beta versions.
+	RuleDeprecateBeforeRemoval = PolicyRule{
+		ID:          "kubernetes/rule-4a",
+		Description: "Beta API versions must be deprecated before they are removed",
+	}
+	// RuleNoGARemoval is the Kubernetes deprecation policy's Rule #4a
+	// for the GA versions.
+	RuleNoGARemoval = PolicyRule{
+		ID:          "kubernetes/rule-4a-ga",
+		Description: "GA API versions may be marked as deprecated, but must not be removed",
+	}
+)
+
+// PolicyViolation is a change that violates a rule of a policy.
+type PolicyViolation struct {
+	// Rule is the violated rule.
+	Rule PolicyRule `json:"rule"`
+	// Version is the name of the API version the violation is found in.
+	Version string `json:"version"`
+	// Maturity is the maturity of the API version.
+	Maturity Maturity `json:"maturity"`
+	// Path is the JSONPath to the changed field, if the violation is
+	// caused by a schema change.
+	Path string `json:"path,omitempty"`
+	// ChangeType is the type of the schema change causing the violation,
+	// if any.
+	ChangeType ChangeType `json:"changeType,omitempty"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// String returns a human-readable representation of the violation
+// including the violated rule.
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("[%s] version %q (%s): %s (violates: %s)", v.Rule.ID, v.Version, v.Maturity, v.Message, v.Rule.Description)
+}
+
+// CheckPolicy returns the changes between the base and the revision CRDs
+// that violate the policy configured with WithPolicy. Without a policy,
+// no violations are reported and GetBreakingChanges should be used instead.
+func (d *RevisionDiff) CheckPolicy() ([]PolicyViolation, error) {
+	switch d.policy {
+	case PolicyNone, "":
+		return nil, nil
+	case PolicyKubernetes:
+		breaking, err := d.GetBreakingChanges()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compute the breaking changes for the policy check")
+		}
+		return d.CheckPolicyChanges(breaking)
+	default:
+		return nil, errors.Errorf("unknown policy: %s", d.policy)
+	}
+}
+
+// CheckPolicyChanges returns the violations of the configured policy by
+// the specified breaking changes, which have been computed with
+// GetBreakingChanges or GetChanges, so that the diff is not computed
+// again.
+func (d *RevisionDiff) CheckPolicyChanges(breaking map[string]*diff.Diff) ([]PolicyViolation, error) {
+	switch d.policy {
+	case PolicyNone, "":
+		return nil, nil
+	case PolicyKubernetes:
+		return d.checkKubernetesPolicy(breaking), nil
+	default:
+		return nil, errors.Errorf("unknown policy: %s", d.policy)
+	}
+}
+
+func (d *RevisionDiff) checkKubernetesPolicy(breaking map[string]*diff.Diff) []PolicyViolation {
+	revisionVersions := make(map[string]bool, len(d.revisionCRD.Spec.Versions))
+	for _, v := range d.revisionCRD.Spec.Versions {
+		revisionVersions[v.Name] = true
+	}
+	violations := checkNoRemovalWithinVersion(breaking, revisionVersions)
+	violations = append(violations, checkVersionRemovals(d.baseCRD, revisionVersions)...)
+	baseDeprecated := make(map[string]bool, len(d.baseCRD.Spec.Versions))
+	for _, bv := range d.baseCRD.Spec.Versions {
+		baseDeprecated[bv.Name] = bv.Deprecated
+	}
+	violations = append(violations, checkDeprecations(d.revisionCRD, baseDeprecated)...)
+	sortViolations(violations)
+	return violations
+}
+
+// checkNoRemovalWithinVersion checks the breaking changes of the specified
+// versions against Rule #1: alpha versions can be changed in incompatible
+// ways, beta and GA versions cannot. The fields of the removed versions
+// are all deleted, which is checked against Rule #4a instead.
+func checkNoRemovalWithinVersion(breaking map[string]*diff.Diff, versions map[string]bool) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, v := range SortedVersionNames(breaking) {
+		m := VersionMaturity(v)
+		if !versions[v] || m == MaturityAlpha {
+			continue
+		}
+		for _, c := range FlattenDiff(breaking[v]) {
+			violations = append(violations, PolicyViolation{
+				Rule:       RuleNoRemovalWithinVersion,
+				Version:    v,
+				Maturity:   m,
+				Path:       c.Path,
+				ChangeType: c.ChangeType,
+				Message:    fmt.Sprintf("breaking change %s at %q within the version", c.ChangeType, c.Path),
+			})
+		}
+	}
+	return violations
+}
+
+// checkVersionRemovals checks the versions of the base CRD missing in
+// the specified revision versions against Rule #4a: removed beta versions
+// must have been deprecated and GA versions cannot be removed.
+func checkVersionRemovals(base *v1.CustomResourceDefinition, revisionVersions map[string]bool) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, bv := range base.Spec.Versions {
+		if revisionVersions[bv.Name] {
+			continue
+		}
+		m := VersionMaturity(bv.Name)
+		switch {
+		case m == MaturityGA:
+			violations = append(violations, PolicyViolation{
+				Rule:     RuleNoGARemoval,
+				Version:  bv.Name,
+				Maturity: m,
+				Message:  "GA version is removed",
+			})
+		case m == MaturityBeta && !bv.Deprecated:
+			violations = append(violations, PolicyViolation{
+				Rule:     RuleDeprecateBeforeRemoval,
+				Version:  bv.Name,
+				Maturity: m,
+				Message:  "version is removed but it's not marked as deprecated in the base",
+			})
+		}
+	}
+	return violations
+}
+
+// checkDeprecations checks the deprecated versions of the specified CRD
+// that are not in the specified previously deprecated versions against
+// Rule #3: a newly deprecated version needs a served replacement that is
+// at least as stable.
+func checkDeprecations(crd *v1.CustomResourceDefinition, previouslyDeprecated map[string]bool) []PolicyViolation {
+	mostStable := -1
+	for _, v := range crd.Spec.Versions {
+		if v.Served && !v.Deprecated {
+			mostStable = max(mostStable, maturityLevels[VersionMaturity(v.Name)])
+		}
+	}
+	var violations []PolicyViolation
+	for _, v := range crd.Spec.Versions {
+		if !v.Deprecated || previouslyDeprecated[v.Name] {
+			continue
+		}
+		m := VersionMaturity(v.Name)
+		if maturityLevels[m] > mostStable {
+			violations = append(violations, PolicyViolation{
+				Rule:     RuleNoDeprecationForLessStable,
+				Version:  v.Name,
+				Maturity: m,
+				Message:  "version is deprecated without a served, non-deprecated version of the same or higher maturity",
+			})
+		}
+	}
+	return violations
+}
+
+// CheckPolicy returns the violations of the policy configured with
+// WithSelfDiffPolicy by the versions of the CRD.
+func (d *SelfDiff) CheckPolicy() ([]PolicyViolation, error) {
+	return d.CheckPolicyChanges(nil)
+}
+
+// CheckPolicyChanges returns the violations of the configured policy by
+// the versions of the CRD and the specified breaking changes between them.
+// With PolicyKubernetes, the breaking changes between the consecutive
+// versions are allowed as the API elements may be removed by incrementing
+// the version, so only the deprecations of the versions are checked.
+func (d *SelfDiff) CheckPolicyChanges(map[string]*diff.Diff) ([]PolicyViolation, error) {
+	switch d.policy {
+	case PolicyNone, "":
+		return nil, nil
+	case PolicyKubernetes:
+		violations := checkDeprecations(d.crd, nil)
+		sortViolations(violations)
+		return violations, nil
+	default:
+		return nil, errors.Errorf("unknown policy: %s", d.policy)
+	}
+}
+
+// sortViolations sorts the violations by their versions, rules and paths.
+func sortViolations(violations []PolicyViolation) {
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		if a.Rule.ID != b.Rule.ID {
+			return a.Rule.ID < b.Rule.ID
+		}
+		return a.Path < b.Path
+	})
+}
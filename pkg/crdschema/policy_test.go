@@ -0,0 +1,254 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestVersionMaturity(t *testing.T) {
+	tests := map[string]struct {
+		version string
+		want    Maturity
+	}{
+		"Alpha":   {version: "v1alpha1", want: MaturityAlpha},
+		"Beta":    {version: "v2beta3", want: MaturityBeta},
+		"GA":      {version: "v1", want: MaturityGA},
+		"Unknown": {version: "latest", want: MaturityGA},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := VersionMaturity(tt.version); got != tt.want {
+				t.Errorf("VersionMaturity(%q): got %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckKubernetesPolicy(t *testing.T) {
+	// renameVersions renames the v1beta1 and v1beta2 versions
+	// declared in testdata/base.yaml.
+	renameVersions := func(first, second string) crdModifier {
+		return func(r *v1.CustomResourceDefinition) {
+			r.Spec.Versions[0].Name = first
+			r.Spec.Versions[1].Name = second
+		}
+	}
+	deprecate := func(index int) crdModifier {
+		return func(r *v1.CustomResourceDefinition) {
+			r.Spec.Versions[index].Deprecated = true
+		}
+	}
+	removeVersion := func(index int) crdModifier {
+		return func(r *v1.CustomResourceDefinition) {
+			r.Spec.Versions = append(r.Spec.Versions[:index], r.Spec.Versions[index+1:]...)
+		}
+	}
+	removeTags := func(r *v1.CustomResourceDefinition) {
+		removeSpecForProviderProperty(r, 0, "tags")
+	}
+
+	type violation struct {
+		rule    string
+		version string
+		path    string
+	}
+	tests := map[string]struct {
+		reason            string
+		baseModifiers     []crdModifier
+		revisionModifiers []crdModifier
+		want              []violation
+	}{
+		"NoChanges": {
+			reason: "No violations should be reported if there are no changes",
+		},
+		"BreakingChangeInAlpha": {
+			reason:            "Breaking changes are allowed in alpha versions",
+			baseModifiers:     []crdModifier{renameVersions("v1alpha1", "v1alpha2")},
+			revisionModifiers: []crdModifier{renameVersions("v1alpha1", "v1alpha2"), removeTags},
+		},
+		"BreakingChangeInBeta": {
+			reason:            "Breaking changes are not allowed in beta versions",
+			revisionModifiers: []crdModifier{removeTags},
+			want: []violation{
+				{rule: RuleNoRemovalWithinVersion.ID, version: "v1beta1", path: "spec.forProvider.tags"},
+			},
+		},
+		"BreakingChangeInGA": {
+			reason:            "Breaking changes are not allowed in GA versions",
+			baseModifiers:     []crdModifier{renameVersions("v1", "v2")},
+			revisionModifiers: []crdModifier{renameVersions("v1", "v2"), removeTags},
+			want: []violation{
+				{rule: RuleNoRemovalWithinVersion.ID, version: "v1", path: "spec.forProvider.tags"},
+			},
+		},
+		"RemovedAlpha": {
+			reason:            "Alpha versions can be removed without deprecation",
+			baseModifiers:     []crdModifier{renameVersions("v1alpha1", "v1beta1")},
+			revisionModifiers: []crdModifier{renameVersions("v1alpha1", "v1beta1"), removeVersion(0)},
+		},
+		"RemovedBetaNotDeprecated": {
+			reason:            "Beta versions must be deprecated in the base before they are removed",
+			revisionModifiers: []crdModifier{removeVersion(0)},
+			want: []violation{
+				{rule: RuleDeprecateBeforeRemoval.ID, version: "v1beta1"},
+			},
+		},
+		"RemovedBetaDeprecated": {
+			reason:            "Deprecated beta versions can be removed",
+			baseModifiers:     []crdModifier{deprecate(0)},
+			revisionModifiers: []crdModifier{removeVersion(0)},
+		},
+		"RemovedGA": {
+			reason:            "GA versions cannot be removed even if they are deprecated",
+			baseModifiers:     []crdModifier{renameVersions("v1", "v2"), deprecate(0)},
+			revisionModifiers: []crdModifier{renameVersions("v1", "v2"), removeVersion(0)},
+			want: []violation{
+				{rule: RuleNoGARemoval.ID, version: "v1"},
+			},
+		},
+		"DeprecatedInFavorOfLessStable": {
+			reason:            "A GA version cannot be deprecated in favor of a beta version",
+			baseModifiers:     []crdModifier{renameVersions("v1", "v2beta1")},
+			revisionModifiers: []crdModifier{renameVersions("v1", "v2beta1"), deprecate(0)},
+			want: []violation{
+				{rule: RuleNoDeprecationForLessStable.ID, version: "v1"},
+			},
+		},
+		"DeprecatedInFavorOfSameMaturity": {
+			reason:            "A beta version can be deprecated in favor of another beta version",
+			revisionModifiers: []crdModifier{deprecate(0)},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			base, err := loadCRD("testdata/base.yaml", false)
+			if err != nil {
+				t.Fatalf("loadCRD(...): %v", err)
+			}
+			revision, err := loadCRD("testdata/base.yaml", false)
+			if err != nil {
+				t.Fatalf("loadCRD(...): %v", err)
+			}
+			for _, m := range tt.baseModifiers {
+				m(base)
+			}
+			for _, m := range tt.revisionModifiers {
+				m(revision)
+			}
+			d := &RevisionDiff{baseCRD: base, revisionCRD: revision, policy: PolicyKubernetes}
+			violations, err := d.CheckPolicy()
+			if err != nil {
+				t.Fatalf("\n%s\nCheckPolicy(): unexpected error: %v", tt.reason, err)
+			}
+			var got []violation
+			for _, v := range violations {
+				got = append(got, violation{rule: v.Rule.ID, version: v.Version, path: v.Path})
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(violation{})); diff != "" {
+				t.Errorf("\n%s\nCheckPolicy(): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKubernetesPolicyRemovedVersion(t *testing.T) {
+	base, err := loadCRD("testdata/base.yaml", false)
+	if err != nil {
+		t.Fatalf("loadCRD(...): %v", err)
+	}
+	revision, err := loadCRD("testdata/base.yaml", false)
+	if err != nil {
+		t.Fatalf("loadCRD(...): %v", err)
+	}
+	base.Spec.Versions[0].Deprecated = true
+	revision.Spec.Versions = revision.Spec.Versions[1:]
+	d := &RevisionDiff{baseCRD: base, revisionCRD: revision, policy: PolicyKubernetes}
+	c, err := GetChanges(d)
+	if err != nil {
+		t.Fatalf("GetChanges(): unexpected error: %v", err)
+	}
+	var got []string
+	for _, sc := range FlattenDiff(c.Breaking["v1beta1"]) {
+		got = append(got, sc.Path+":"+string(sc.ChangeType))
+	}
+	want := []string{"apiVersion:field_deleted", "kind:field_deleted", "metadata:field_deleted", "spec:field_deleted", "status:field_deleted"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("A removed version should be reported with all its top-level fields deleted: -want, +got:\n%s", diff)
+	}
+	violations, err := d.CheckPolicyChanges(c.Breaking)
+	if err != nil {
+		t.Fatalf("CheckPolicyChanges(...): unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("The removal of a deprecated beta version should not violate the policy: %v", violations)
+	}
+}
+
+func TestSelfDiffCheckKubernetesPolicy(t *testing.T) {
+	tests := map[string]struct {
+		reason    string
+		modifiers []crdModifier
+		want      []string
+	}{
+		"BreakingChangeBetweenVersions": {
+			reason: "Breaking changes between the versions should be allowed",
+			modifiers: []crdModifier{func(r *v1.CustomResourceDefinition) {
+				removeSpecForProviderProperty(r, 1, "tags")
+			}},
+		},
+		"DeprecatedInFavorOfSameMaturity": {
+			reason: "A beta version can be deprecated in favor of another beta version",
+			modifiers: []crdModifier{func(r *v1.CustomResourceDefinition) {
+				r.Spec.Versions[0].Deprecated = true
+			}},
+		},
+		"DeprecatedInFavorOfLessStable": {
+			reason: "A GA version cannot be deprecated in favor of a beta version",
+			modifiers: []crdModifier{func(r *v1.CustomResourceDefinition) {
+				r.Spec.Versions[0].Name = "v1"
+				r.Spec.Versions[0].Deprecated = true
+				r.Spec.Versions[1].Name = "v2beta1"
+			}},
+			want: []string{RuleNoDeprecationForLessStable.ID + ":v1"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			crd, err := loadCRD("testdata/base.yaml", false)
+			if err != nil {
+				t.Fatalf("loadCRD(...): %v", err)
+			}
+			for _, m := range tt.modifiers {
+				m(crd)
+			}
+			d := &SelfDiff{crd: crd, policy: PolicyKubernetes}
+			violations, err := d.CheckPolicy()
+			if err != nil {
+				t.Fatalf("\n%s\nCheckPolicy(): unexpected error: %v", tt.reason, err)
+			}
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Rule.ID+":"+v.Version)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("\n%s\nCheckPolicy(): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
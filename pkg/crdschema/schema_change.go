@@ -15,9 +15,14 @@
 package crdschema
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
 	kinoapi "github.com/getkin/kin-openapi/openapi3"
 	"github.com/oasdiff/oasdiff/diff"
 	"github.com/oasdiff/oasdiff/utils"
+	"github.com/pkg/errors"
 )
 
 // ChangeType represents the type of schema change detected
@@ -33,10 +38,13 @@ const (
 // It's a flattened representation of changes extracted from oasdiff's
 // nested diff structure.
 type SchemaChange struct {
-	// Path is the JSONPath to the changed field (e.g., "spec.forProvider.instanceName")
+	// Path is the JSONPath to the changed field (e.g., "spec.forProvider.instanceName").
+	// Property names that are not plain identifiers use the bracket
+	// notation with escaping (e.g., "spec.forProvider.tags['example.com/key']").
 	Path string `json:"path"`
 
-	// PathParts is the path split into components for easier processing
+	// PathParts is the path split into components for easier processing.
+	// The components are the unescaped property names.
 	PathParts []string `json:"pathParts"`
 
 	// ChangeType indicates what kind of change occurred
@@ -48,6 +56,18 @@ type SchemaChange struct {
 	// RawSchemaDiff contains the full SchemaDiff object for this change.
 	// This is not serialized to JSON but can be used for advanced processing.
 	RawSchemaDiff *diff.SchemaDiff `json:"-"`
+
+	// BaseSchema is the subschema of the changed field in the base, if any.
+	// This is not serialized to JSON, see Explanation.
+	BaseSchema *kinoapi.Schema `json:"-"`
+
+	// RevisionSchema is the subschema of the changed field in the
+	// revision, if any. This is not serialized to JSON, see Explanation.
+	RevisionSchema *kinoapi.Schema `json:"-"`
+
+	// Explanation contains the base and revision subschemas of the
+	// changed field if the change has been explained.
+	Explanation *ChangeExplanation `json:"explanation,omitempty"`
 }
 
 // VersionChanges contains all schema changes for a specific CRD version
@@ -66,6 +86,61 @@ type VersionChanges struct {
 type ChangeReport struct {
 	// Versions maps version names to their changes
 	Versions map[string]*VersionChanges `json:"versions"`
+
+	// Fingerprint is a stable digest of the reported changes, which can
+	// be used to detect whether two reports are identical.
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// PolicyViolations are the changes violating the policy the
+	// changes are checked against, if any.
+	PolicyViolations []PolicyViolation `json:"policyViolations,omitempty"`
+
+	// Summary contains the numbers of the changes in the report, if
+	// the report has been summarized.
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// ComputeFingerprint returns a stable digest of the versions and the
+// changes in the report. Reports with the same changes have the same
+// fingerprint regardless of the order the changes were computed in,
+// and neither the report's Fingerprint field nor the explanations of
+// the changes are included in the digest. A nil report, a report without
+// versions and a report with an empty map of versions have the same
+// fingerprint.
+func (r *ChangeReport) ComputeFingerprint() (string, error) {
+	versions := map[string]*VersionChanges{}
+	if r != nil {
+		versions = withoutExplanations(r.Versions)
+	}
+	// JSON objects are marshaled with sorted keys and the changes are
+	// sorted when flattened, so the serialized form is canonical.
+	buff, err := json.Marshal(versions)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the change report")
+	}
+	sum := sha256.Sum256(buff)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// withoutExplanations returns a copy of the specified version changes
+// with the explanations of the changes removed. The copy of nil version
+// changes is an empty map.
+func withoutExplanations(versions map[string]*VersionChanges) map[string]*VersionChanges {
+	result := make(map[string]*VersionChanges, len(versions))
+	for v, vc := range versions {
+		if vc == nil {
+			result[v] = nil
+			continue
+		}
+		c := *vc
+		c.Changes = make([]SchemaChange, len(vc.Changes))
+		for i, sc := range vc.Changes {
+			sc.Explanation = nil
+			c.Changes[i] = sc
+		}
+		result[v] = &c
+	}
+	return result
 }
 
 // Empty returns true if the report contains no changes
@@ -95,6 +170,24 @@ func (r *ChangeReport) TotalChanges() int {
 	return count
 }
 
+// TotalChangesByType returns the total number of changes of each type
+// across all versions
+func (r *ChangeReport) TotalChangesByType() map[ChangeType]int {
+	counts := make(map[ChangeType]int)
+	if r == nil {
+		return counts
+	}
+	for _, vc := range r.Versions {
+		if vc == nil {
+			continue
+		}
+		for _, c := range vc.Changes {
+			counts[c.ChangeType]++
+		}
+	}
+	return counts
+}
+
 // TypeChangeDetails is the diff information for a type change
 type TypeChangeDetails struct {
 	// OldType is the type of the base schema
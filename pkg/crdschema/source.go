@@ -0,0 +1,267 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// InputFormat is the format of a schema input to be compared.
+type InputFormat string
+
+const (
+	// InputFormatCRD is a CustomResourceDefinition manifest file.
+	InputFormatCRD InputFormat = "crd"
+	// InputFormatOpenAPI is a raw OpenAPI v3 schema file in YAML or JSON.
+	InputFormatOpenAPI InputFormat = "openapi"
+	// InputFormatGoTypes is a Go package pattern for the API types from
+	// which the CRD schemas are generated with controller-gen.
+	InputFormatGoTypes InputFormat = "go"
+
+	// DefaultSchemaVersion is the version name assigned to a raw
+	// OpenAPI v3 schema if no version name is specified.
+	DefaultSchemaVersion = "v1"
+)
+
+// SourceOptions configure how a schema input to be compared is loaded.
+type SourceOptions struct {
+	// Format is the format of the input. Defaults to InputFormatCRD.
+	Format InputFormat
+	// Version is the CRD version name assigned to a raw OpenAPI v3 schema
+	// so that it can be compared against the same version in a CRD.
+	// Defaults to DefaultSchemaVersion.
+	Version string
+	// Group and Kind are the API group and the kind assigned to a raw
+	// OpenAPI v3 schema. If not specified, they are read from the
+	// x-kubernetes-group-version-kind extension of the schema, if any.
+	Group string
+	Kind  string
+	// CRDName is the name of the CRD (e.g., "instances.ec2.aws.upbound.io")
+	// to be selected if the Go API types declare more than one kind.
+	CRDName string
+}
+
+// loadSource loads the CRD described by the specified input.
+func loadSource(path string, src SourceOptions, enableUpjetExtensions bool) (*v1.CustomResourceDefinition, error) {
+	switch src.Format {
+	case InputFormatCRD, "":
+		return loadCRD(path, enableUpjetExtensions)
+	case InputFormatOpenAPI:
+		c, err := loadOpenAPISchema(path, src)
+		if err != nil {
+			return nil, err
+		}
+		return c, errors.Wrap(applyExtensions(c, enableUpjetExtensions), "failed to apply the diff extensions")
+	case InputFormatGoTypes:
+		c, err := generateCRD(path, src.CRDName)
+		if err != nil {
+			return nil, err
+		}
+		return c, errors.Wrap(applyExtensions(c, enableUpjetExtensions), "failed to apply the diff extensions")
+	default:
+		return nil, errors.Errorf("unknown input format: %s", src.Format)
+	}
+}
+
+// gvkExtension is the x-kubernetes-group-version-kind extension of
+// the OpenAPI v3 schemas published by the Kubernetes API server.
+type gvkExtension struct {
+	GVKs []struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"x-kubernetes-group-version-kind"`
+}
+
+// groupKind returns the group and the kind of the specified version in
+// the extension. If the version is not found, the first group and kind
+// are returned.
+func (e gvkExtension) groupKind(version string) (string, string) {
+	if len(e.GVKs) == 0 {
+		return "", ""
+	}
+	gvk := e.GVKs[0]
+	for _, g := range e.GVKs {
+		if g.Version == version {
+			gvk = g
+			break
+		}
+	}
+	return gvk.Group, gvk.Kind
+}
+
+// loadOpenAPISchema loads a raw OpenAPI v3 schema from the specified file
+// and wraps it in a CRD with a single version with the name, the group
+// and the kind of the specified source options.
+func loadOpenAPISchema(path string, src SourceOptions) (*v1.CustomResourceDefinition, error) {
+	buff, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the OpenAPI v3 schema from file: %s", path)
+	}
+	s := &v1.JSONSchemaProps{}
+	if err := apiyaml.Unmarshal(buff, s); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal OpenAPI v3 schema from file: %s", path)
+	}
+	if s.Type == "" && len(s.Properties) == 0 {
+		return nil, errors.Errorf("file does not contain an OpenAPI v3 object schema: %s", path)
+	}
+	version := src.Version
+	if version == "" {
+		version = DefaultSchemaVersion
+	}
+	ext := gvkExtension{}
+	if err := apiyaml.Unmarshal(buff, &ext); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the x-kubernetes-group-version-kind extension from file: %s", path)
+	}
+	group, kind := ext.groupKind(version)
+	if src.Group != "" {
+		group = src.Group
+	}
+	if src.Kind != "" {
+		kind = src.Kind
+	}
+	return &v1.CustomResourceDefinition{
+		Spec: v1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: v1.CustomResourceDefinitionNames{
+				Kind: kind,
+			},
+			Versions: []v1.CustomResourceDefinitionVersion{
+				{
+					Name: version,
+					Schema: &v1.CustomResourceValidation{
+						OpenAPIV3Schema: s,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// generateCRD generates the CRDs for the Go API types in the packages
+// matching the specified pattern using controller-gen's CRD generator,
+// and returns the one with the specified name. The name can be omitted
+// if the packages declare a single kind.
+func generateCRD(pattern, name string) (*v1.CustomResourceDefinition, error) {
+	crds, err := generateCRDs(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		if len(crds) != 1 {
+			return nil, errors.Errorf("found %d CRDs in the Go API types %q, the CRD name must be specified to select one of: %s",
+				len(crds), pattern, strings.Join(sortedCRDNames(crds), ", "))
+		}
+		for _, c := range crds {
+			return c, nil
+		}
+	}
+	c, ok := crds[name]
+	if !ok {
+		return nil, errors.Errorf("CRD %q not found in the Go API types %q, available CRDs: %s",
+			name, pattern, strings.Join(sortedCRDNames(crds), ", "))
+	}
+	return c, nil
+}
+
+func sortedCRDNames(crds map[string]*v1.CustomResourceDefinition) []string {
+	names := make([]string, 0, len(crds))
+	for n := range crds {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generateCRDs generates the CRDs for the Go API types in the packages
+// matching the specified pattern, keyed by the CRD names.
+func generateCRDs(pattern string) (map[string]*v1.CustomResourceDefinition, error) {
+	roots, err := loader.LoadRoots(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the Go packages: %s", pattern)
+	}
+	g := crd.Generator{}
+	reg := &markers.Registry{}
+	if err := g.RegisterMarkers(reg); err != nil {
+		return nil, errors.Wrap(err, "failed to register the CRD generator markers")
+	}
+	parser := &crd.Parser{
+		Collector: &markers.Collector{Registry: reg},
+		Checker:   &loader.TypeChecker{NodeFilters: []loader.NodeFilter{g.CheckFilter()}},
+	}
+	crd.AddKnownTypes(parser)
+	for _, r := range roots {
+		parser.NeedPackage(r)
+	}
+
+	result := make(map[string]*v1.CustomResourceDefinition)
+	metav1Pkg := crd.FindMetav1(roots)
+	if metav1Pkg == nil {
+		return nil, errors.Errorf("no API types found in the Go packages: %s", pattern)
+	}
+	for _, gk := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(gk, nil)
+		c := parser.CustomResourceDefinitions[gk]
+		result[c.Name] = &c
+	}
+	// type errors are expected from the partial type checking,
+	// as in controller-gen.
+	if err := packageErrors(roots, packages.TypeError); err != nil {
+		return nil, errors.Wrapf(err, "failed to generate the CRDs from the Go packages: %s", pattern)
+	}
+	if len(result) == 0 {
+		return nil, errors.Errorf("no API types found in the Go packages: %s", pattern)
+	}
+	return result, nil
+}
+
+// packageErrors returns the errors in the given package graph except
+// the errors of the specified kinds.
+func packageErrors(roots []*loader.Package, skipKinds ...packages.ErrorKind) error {
+	pkgs := make([]*packages.Package, len(roots))
+	for i, r := range roots {
+		pkgs[i] = r.Package
+	}
+	var msgs []string
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, err := range p.Errors {
+			skip := false
+			for _, k := range skipKinds {
+				if err.Kind == k {
+					skip = true
+					break
+				}
+			}
+			if !skip {
+				msgs = append(msgs, err.Error())
+			}
+		}
+	})
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	testWidgetSchemaPath = "testdata/widget-schema.yaml"
+	testWidgetTypesPath  = "./testdata/apis/v1alpha1"
+	testWidgetVersion    = "v1alpha1"
+)
+
+func TestRevisionDiffSources(t *testing.T) {
+	openAPISource := SourceOptions{Format: InputFormatOpenAPI, Version: testWidgetVersion}
+	goTypesSource := SourceOptions{Format: InputFormatGoTypes}
+	type args struct {
+		basePath     string
+		revisionPath string
+		opts         []RevisionDiffOption
+	}
+	type want struct {
+		changes  []string
+		breaking bool
+		err      bool
+	}
+	tests := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"OpenAPIToGoTypes": {
+			reason: "An optional field added in the Go API types should be reported as a non-breaking change",
+			args: args{
+				basePath:     testWidgetSchemaPath,
+				revisionPath: testWidgetTypesPath,
+				opts:         []RevisionDiffOption{WithBaseSource(openAPISource), WithRevisionSource(goTypesSource)},
+			},
+			want: want{
+				changes: []string{"spec.forProvider.size:field_added"},
+			},
+		},
+		"GoTypesToOpenAPI": {
+			reason: "A field missing in the OpenAPI schema should be reported as a breaking change",
+			args: args{
+				basePath:     testWidgetTypesPath,
+				revisionPath: testWidgetSchemaPath,
+				opts:         []RevisionDiffOption{WithBaseSource(goTypesSource), WithRevisionSource(openAPISource)},
+			},
+			want: want{
+				changes:  []string{"spec.forProvider.size:field_deleted"},
+				breaking: true,
+			},
+		},
+		"GoTypesWithCRDName": {
+			reason: "The generated CRD should be selectable by its name",
+			args: args{
+				basePath:     testWidgetTypesPath,
+				revisionPath: testWidgetTypesPath,
+				opts: []RevisionDiffOption{
+					WithBaseSource(SourceOptions{Format: InputFormatGoTypes, CRDName: "widgets.test.upbound.io"}),
+					WithRevisionSource(goTypesSource),
+				},
+			},
+			want: want{
+				changes: []string{},
+			},
+		},
+		"GoTypesWithUnknownCRDName": {
+			reason: "Selecting a CRD not declared in the Go API types should fail",
+			args: args{
+				basePath:     testWidgetTypesPath,
+				revisionPath: testWidgetTypesPath,
+				opts:         []RevisionDiffOption{WithBaseSource(SourceOptions{Format: InputFormatGoTypes, CRDName: "unknown.test.upbound.io"})},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"CRDAsOpenAPISchema": {
+			reason: "Loading a CRD manifest as an OpenAPI v3 schema should fail",
+			args: args{
+				basePath:     "testdata/base.yaml",
+				revisionPath: "testdata/base.yaml",
+				opts:         []RevisionDiffOption{WithBaseSource(openAPISource)},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"UnknownFormat": {
+			reason: "An unknown input format should fail",
+			args: args{
+				basePath:     "testdata/base.yaml",
+				revisionPath: "testdata/base.yaml",
+				opts:         []RevisionDiffOption{WithRevisionSource(SourceOptions{Format: "unknown"})},
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewRevisionDiff(tt.args.basePath, tt.args.revisionPath, tt.args.opts...)
+			if tt.want.err {
+				if err == nil {
+					t.Errorf("\n%s\nNewRevisionDiff(...): expected an error", tt.reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("\n%s\nNewRevisionDiff(...): unexpected error: %v", tt.reason, err)
+			}
+			rawDiff, err := d.GetRawDiff()
+			if err != nil {
+				t.Fatalf("\n%s\nGetRawDiff(): unexpected error: %v", tt.reason, err)
+			}
+			got := make([]string, 0, len(tt.want.changes))
+			for _, c := range FlattenDiff(rawDiff[testWidgetVersion]) {
+				got = append(got, c.Path+":"+string(c.ChangeType))
+			}
+			if diff := cmp.Diff(tt.want.changes, got); diff != "" {
+				t.Errorf("\n%s\nFlattenDiff(...): -want, +got:\n%s", tt.reason, diff)
+			}
+			breaking, err := d.GetBreakingChanges()
+			if err != nil {
+				t.Fatalf("\n%s\nGetBreakingChanges(): unexpected error: %v", tt.reason, err)
+			}
+			if got := !emptyDiffMap(breaking); got != tt.want.breaking {
+				t.Errorf("\n%s\nGetBreakingChanges(): breaking = %v, want %v", tt.reason, got, tt.want.breaking)
+			}
+		})
+	}
+}
+
+func TestOpenAPISchemaGroupKind(t *testing.T) {
+	gvkSchema := filepath.Join(t.TempDir(), "schema.yaml")
+	err := os.WriteFile(gvkSchema, []byte(`type: object
+x-kubernetes-group-version-kind:
+- group: test.upbound.io
+  version: v1alpha1
+  kind: Widget
+- group: test.upbound.io
+  version: v1beta1
+  kind: Gadget
+`), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write the schema: %v", err)
+	}
+	tests := map[string]struct {
+		reason string
+		path   string
+		src    SourceOptions
+		want   schema.GroupKind
+	}{
+		"Extension": {
+			reason: "The group and the kind of the schema version should be read from the x-kubernetes-group-version-kind extension",
+			path:   gvkSchema,
+			src:    SourceOptions{Format: InputFormatOpenAPI, Version: "v1beta1"},
+			want:   schema.GroupKind{Group: "test.upbound.io", Kind: "Gadget"},
+		},
+		"ExtensionOtherVersion": {
+			reason: "The first group and kind in the extension should be used if the schema version is not found",
+			path:   gvkSchema,
+			src:    SourceOptions{Format: InputFormatOpenAPI, Version: "v1"},
+			want:   schema.GroupKind{Group: "test.upbound.io", Kind: "Widget"},
+		},
+		"Options": {
+			reason: "The specified group and kind should override the extension",
+			path:   gvkSchema,
+			src:    SourceOptions{Format: InputFormatOpenAPI, Group: "example.upbound.io", Kind: "Example"},
+			want:   schema.GroupKind{Group: "example.upbound.io", Kind: "Example"},
+		},
+		"NoExtension": {
+			reason: "The group and the kind should be empty if neither specified nor found in the schema",
+			path:   testWidgetSchemaPath,
+			src:    SourceOptions{Format: InputFormatOpenAPI},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewRevisionDiff(testWidgetSchemaPath, tt.path, WithBaseSource(SourceOptions{Format: InputFormatOpenAPI}), WithRevisionSource(tt.src))
+			if err != nil {
+				t.Fatalf("\n%s\nNewRevisionDiff(...): unexpected error: %v", tt.reason, err)
+			}
+			if diff := cmp.Diff(tt.want, d.GroupKind()); diff != "" {
+				t.Errorf("\n%s\nGroupKind(): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/oasdiff/oasdiff/diff"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ThresholdDisabled disables a threshold of Thresholds.
+const ThresholdDisabled = -1
+
+// ChangeCounts are the numbers of the detected changes.
+type ChangeCounts struct {
+	// Breaking is the number of breaking changes.
+	Breaking int `json:"breaking"`
+	// NonBreaking is the number of non-breaking changes.
+	NonBreaking int `json:"nonBreaking"`
+	// ByType maps the change types to the number of changes, both
+	// breaking and non-breaking, of that type.
+	ByType map[ChangeType]int `json:"byType,omitempty"`
+}
+
+// NewChangeCounts returns the change counts from the specified reports of
+// all the changes and of only the breaking changes computed from the same
+// diff.
+func NewChangeCounts(all, breaking *ChangeReport) ChangeCounts {
+	c := ChangeCounts{
+		Breaking: breaking.TotalChanges(),
+	}
+	c.NonBreaking = all.TotalChanges() - c.Breaking
+	if byType := all.TotalChangesByType(); len(byType) > 0 {
+		c.ByType = byType
+	}
+	return c
+}
+
+// CountChanges returns the change counts of the specified schema check.
+func CountChanges(sc SchemaCheck) (ChangeCounts, error) {
+	c, err := GetChanges(sc)
+	if err != nil {
+		return ChangeCounts{}, err
+	}
+	return c.Counts, nil
+}
+
+// Changes are the schema changes of a SchemaCheck. The raw diff is
+// computed once and the breaking changes and the counts are derived
+// from it.
+type Changes struct {
+	// Raw maps the version names to their diffs, including
+	// the non-breaking changes.
+	Raw map[string]*diff.Diff
+	// Breaking maps the version names to their breaking changes.
+	Breaking map[string]*diff.Diff
+	// Counts are the change counts of the raw diff.
+	Counts ChangeCounts
+}
+
+// GetChanges returns the changes of the specified schema check.
+func GetChanges(sc SchemaCheck) (*Changes, error) {
+	rawDiff, err := sc.GetRawDiff()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute the schema changes")
+	}
+	c := &Changes{
+		Raw:      rawDiff,
+		Breaking: filterNonBreaking(rawDiff),
+	}
+	if c.Counts, err = countChanges(c.Raw, c.Breaking); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Diff returns the raw diff if all the changes are to be kept, or
+// otherwise, the breaking changes.
+func (c *Changes) Diff(keepAllChanges bool) map[string]*diff.Diff {
+	if keepAllChanges {
+		return c.Raw
+	}
+	return c.Breaking
+}
+
+// HasBreakingChanges returns true if any breaking change is found.
+func (c *Changes) HasBreakingChanges() bool {
+	return !emptyDiffMap(c.Breaking)
+}
+
+// countChanges returns the change counts of the specified raw diff and
+// the breaking changes filtered from it.
+func countChanges(rawDiff, breakingDiff map[string]*diff.Diff) (ChangeCounts, error) {
+	all, err := GetChangesAsStructured(rawDiff, true)
+	if err != nil {
+		return ChangeCounts{}, err
+	}
+	breaking, err := GetChangesAsStructured(breakingDiff, true)
+	if err != nil {
+		return ChangeCounts{}, err
+	}
+	return NewChangeCounts(all, breaking), nil
+}
+
+// Total returns the total number of changes.
+func (c ChangeCounts) Total() int {
+	return c.Breaking + c.NonBreaking
+}
+
+func (c *ChangeCounts) add(o ChangeCounts) {
+	c.Breaking += o.Breaking
+	c.NonBreaking += o.NonBreaking
+	for t, n := range o.ByType {
+		if c.ByType == nil {
+			c.ByType = make(map[ChangeType]int)
+		}
+		c.ByType[t] += n
+	}
+}
+
+// String returns a human-readable representation of the counts.
+func (c ChangeCounts) String() string {
+	types := make([]string, 0, len(c.ByType))
+	for t := range c.ByType {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	s := fmt.Sprintf("%d breaking, %d non-breaking", c.Breaking, c.NonBreaking)
+	if len(types) == 0 {
+		return s
+	}
+	for i, t := range types {
+		types[i] = fmt.Sprintf("%d %s", c.ByType[ChangeType(t)], t)
+	}
+	return s + " (" + strings.Join(types, ", ") + ")"
+}
+
+// Summary aggregates the change counts of a set of CRDs.
+type Summary struct {
+	// Total is the sum of the change counts of all the CRDs.
+	Total ChangeCounts `json:"total"`
+	// Groups maps the API groups to the change counts of their CRDs.
+	Groups map[string]*ChangeCounts `json:"groups,omitempty"`
+	// Kinds maps the kinds, qualified with their API groups, e.g.,
+	// Certificate.acm.aws.upbound.io, to the change counts of their CRDs.
+	Kinds map[string]*ChangeCounts `json:"kinds,omitempty"`
+}
+
+// NewSummary returns an empty Summary.
+func NewSummary() *Summary {
+	return &Summary{
+		Groups: make(map[string]*ChangeCounts),
+		Kinds:  make(map[string]*ChangeCounts),
+	}
+}
+
+// Add adds the change counts of the CRD with the specified group and kind.
+// CRDs without any changes are also included in the per-group and
+// per-kind counts.
+func (s *Summary) Add(gk schema.GroupKind, c ChangeCounts) {
+	s.Total.add(c)
+	addCounts(s.Groups, gk.Group, c)
+	addCounts(s.Kinds, gk.String(), c)
+}
+
+func addCounts(m map[string]*ChangeCounts, key string, c ChangeCounts) {
+	if m[key] == nil {
+		m[key] = &ChangeCounts{}
+	}
+	m[key].add(c)
+}
+
+// Thresholds are the maximum numbers of changes allowed. A threshold
+// with a negative value, e.g., ThresholdDisabled, is not checked.
+type Thresholds struct {
+	// MaxBreaking is the maximum number of breaking changes.
+	MaxBreaking int
+	// MaxDeletedFields is the maximum number of deleted fields.
+	MaxDeletedFields int
+}
+
+// Enabled returns true if any of the thresholds is to be checked.
+func (t Thresholds) Enabled() bool {
+	return t.MaxBreaking >= 0 || t.MaxDeletedFields >= 0
+}
+
+// CheckThresholds returns the descriptions of the thresholds exceeded by
+// the total change counts of the summary.
+func (s *Summary) CheckThresholds(t Thresholds) []string {
+	var exceeded []string
+	if t.MaxBreaking >= 0 && s.Total.Breaking > t.MaxBreaking {
+		exceeded = append(exceeded, fmt.Sprintf("%d breaking changes exceed the maximum of %d", s.Total.Breaking, t.MaxBreaking))
+	}
+	if deleted := s.Total.ByType[ChangeTypeFieldDeleted]; t.MaxDeletedFields >= 0 && deleted > t.MaxDeletedFields {
+		exceeded = append(exceeded, fmt.Sprintf("%d deleted fields exceed the maximum of %d", deleted, t.MaxDeletedFields))
+	}
+	return exceeded
+}
+
+// RenderText writes a human-readable representation of the summary
+// to the specified writer.
+func (s *Summary) RenderText(w io.Writer) error {
+	lines := []string{"Summary:", "  Total: " + s.Total.String()}
+	lines = append(lines, countLines("Group", s.Groups)...)
+	lines = append(lines, countLines("Kind", s.Kinds)...)
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return errors.Wrap(err, "failed to render the summary")
+}
+
+func countLines(title string, m map[string]*ChangeCounts) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("  %s %s: %s", title, k, m[k]))
+	}
+	return lines
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCountChanges(t *testing.T) {
+	d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil,
+		func(r *v1.CustomResourceDefinition) {
+			removeSpecForProviderProperty(r, 0, "tags")
+			addSpecForProviderProperty(r, 0, "newField", v1.JSONSchemaProps{Type: "string"}, nil)
+			addSpecForProviderProperty(r, 1, "newField", v1.JSONSchemaProps{Type: "string"}, nil)
+		})
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	got, err := CountChanges(d)
+	if err != nil {
+		t.Fatalf("CountChanges(...): unexpected error: %v", err)
+	}
+	want := ChangeCounts{
+		Breaking:    1,
+		NonBreaking: 2,
+		ByType: map[ChangeType]int{
+			ChangeTypeFieldAdded:   2,
+			ChangeTypeFieldDeleted: 1,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CountChanges(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	certificate := schema.GroupKind{Group: "acm.aws.upbound.io", Kind: "Certificate"}
+	validation := schema.GroupKind{Group: "acm.aws.upbound.io", Kind: "CertificateValidation"}
+	bucket := schema.GroupKind{Group: "s3.aws.upbound.io", Kind: "Bucket"}
+
+	s := NewSummary()
+	s.Add(certificate, ChangeCounts{Breaking: 2, NonBreaking: 1, ByType: map[ChangeType]int{ChangeTypeFieldDeleted: 2, ChangeTypeFieldAdded: 1}})
+	s.Add(validation, ChangeCounts{NonBreaking: 1, ByType: map[ChangeType]int{ChangeTypeFieldAdded: 1}})
+	s.Add(bucket, ChangeCounts{Breaking: 1, ByType: map[ChangeType]int{ChangeTypeTypeChanged: 1}})
+
+	wantTotal := ChangeCounts{Breaking: 3, NonBreaking: 2, ByType: map[ChangeType]int{
+		ChangeTypeFieldAdded:   2,
+		ChangeTypeFieldDeleted: 2,
+		ChangeTypeTypeChanged:  1,
+	}}
+	if diff := cmp.Diff(wantTotal, s.Total); diff != "" {
+		t.Errorf("Add(...): total: -want, +got:\n%s", diff)
+	}
+	wantGroup := &ChangeCounts{Breaking: 2, NonBreaking: 2, ByType: map[ChangeType]int{
+		ChangeTypeFieldAdded:   2,
+		ChangeTypeFieldDeleted: 2,
+	}}
+	if diff := cmp.Diff(wantGroup, s.Groups["acm.aws.upbound.io"]); diff != "" {
+		t.Errorf("Add(...): group: -want, +got:\n%s", diff)
+	}
+
+	buff := &bytes.Buffer{}
+	if err := s.RenderText(buff); err != nil {
+		t.Fatalf("RenderText(...): unexpected error: %v", err)
+	}
+	wantText := `Summary:
+  Total: 3 breaking, 2 non-breaking (2 field_added, 2 field_deleted, 1 type_changed)
+  Group acm.aws.upbound.io: 2 breaking, 2 non-breaking (2 field_added, 2 field_deleted)
+  Group s3.aws.upbound.io: 1 breaking, 0 non-breaking (1 type_changed)
+  Kind Bucket.s3.aws.upbound.io: 1 breaking, 0 non-breaking (1 type_changed)
+  Kind Certificate.acm.aws.upbound.io: 2 breaking, 1 non-breaking (1 field_added, 2 field_deleted)
+  Kind CertificateValidation.acm.aws.upbound.io: 0 breaking, 1 non-breaking (1 field_added)
+`
+	if diff := cmp.Diff(wantText, buff.String()); diff != "" {
+		t.Errorf("RenderText(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestSummaryCheckThresholds(t *testing.T) {
+	s := NewSummary()
+	s.Add(schema.GroupKind{Group: "acm.aws.upbound.io", Kind: "Certificate"},
+		ChangeCounts{Breaking: 2, NonBreaking: 1, ByType: map[ChangeType]int{ChangeTypeFieldDeleted: 2, ChangeTypeFieldAdded: 1}})
+
+	tests := map[string]struct {
+		reason     string
+		thresholds Thresholds
+		want       []string
+	}{
+		"Disabled": {
+			reason:     "No thresholds should be exceeded if they are disabled",
+			thresholds: Thresholds{MaxBreaking: ThresholdDisabled, MaxDeletedFields: ThresholdDisabled},
+		},
+		"WithinThresholds": {
+			reason:     "No thresholds should be exceeded if the counts are at the maximums",
+			thresholds: Thresholds{MaxBreaking: 2, MaxDeletedFields: 2},
+		},
+		"Exceeded": {
+			reason:     "Thresholds below the counts should be reported as exceeded",
+			thresholds: Thresholds{MaxBreaking: 0, MaxDeletedFields: 1},
+			want: []string{
+				"2 breaking changes exceed the maximum of 0",
+				"2 deleted fields exceed the maximum of 1",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, s.CheckThresholds(tt.thresholds)); diff != "" {
+				t.Errorf("\n%s\nCheckThresholds(...): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1alpha1 contains the test API types for generating CRD
+// schemas from Go types.
+// +kubebuilder:object:generate=true
+// +groupName=test.upbound.io
+// +versionName=v1alpha1
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetParameters are the configurable fields of a Widget.
+type WidgetParameters struct {
+	// Name of the widget.
+	Name string `json:"name"`
+	// Size of the widget.
+	// +optional
+	Size *int64 `json:"size,omitempty"`
+}
+
+// WidgetSpec defines the desired state of a Widget.
+type WidgetSpec struct {
+	ForProvider WidgetParameters `json:"forProvider"`
+}
+
+// Widget is a test API type.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WidgetSpec `json:"spec"`
+}
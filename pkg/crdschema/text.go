@@ -0,0 +1,400 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	kinoapi "github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/oasdiff/diff"
+	"github.com/oasdiff/oasdiff/utils"
+	"github.com/pkg/errors"
+)
+
+// TextMode is the level of detail of a text report.
+type TextMode string
+
+const (
+	// TextModeVerbose renders the schema diff as a nested tree of all
+	// the changes, including the changes to the validation keywords and
+	// the descriptions.
+	TextModeVerbose TextMode = "verbose"
+	// TextModeTerse renders a single line per added, deleted or type
+	// changed field.
+	TextModeTerse TextMode = "terse"
+	// TextModePaths renders only the paths of the added, deleted or type
+	// changed fields, one per line.
+	TextModePaths TextMode = "paths"
+)
+
+// ANSI escape sequences used when colors are enabled.
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// TextRenderer renders the computed diffs as human-readable text.
+type TextRenderer struct {
+	mode    TextMode
+	color   bool
+	explain bool
+}
+
+// TextRendererOption is a functional option to configure the behavior of
+// a TextRenderer.
+type TextRendererOption func(*TextRenderer)
+
+// WithTextMode configures the level of detail of the rendered text.
+func WithTextMode(m TextMode) TextRendererOption {
+	return func(r *TextRenderer) {
+		r.mode = m
+	}
+}
+
+// WithTextColor configures whether the additions, deletions and
+// modifications are highlighted with ANSI colors.
+func WithTextColor(color bool) TextRendererOption {
+	return func(r *TextRenderer) {
+		r.color = color
+	}
+}
+
+// WithTextExplain configures whether the base and revision subschemas and
+// their descriptions are rendered for each added, deleted or type changed
+// field.
+func WithTextExplain(explain bool) TextRendererOption {
+	return func(r *TextRenderer) {
+		r.explain = explain
+	}
+}
+
+// NewTextRenderer returns a new TextRenderer. By default, the diffs are
+// rendered in the verbose mode without colors.
+func NewTextRenderer(opts ...TextRendererOption) *TextRenderer {
+	r := &TextRenderer{
+		mode: TextModeVerbose,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Render writes the specified diff to the specified writer. Nothing is
+// written for an empty diff. The diff is not modified.
+func (r *TextRenderer) Render(w io.Writer, d *diff.Diff) error {
+	if d == nil || d.Empty() {
+		return nil
+	}
+	p := &textPrinter{w: w, color: r.color}
+	switch r.mode {
+	case TextModeTerse, TextModePaths:
+		for _, c := range FlattenDiff(d) {
+			p.println(0, changeColor(c.ChangeType), r.changeLine(c))
+			if err := r.explainChange(p, 1, c); err != nil {
+				return err
+			}
+		}
+	default:
+		sd := extractSchemaDiff(d)
+		if sd == nil || sd.Empty() {
+			return nil
+		}
+		p.item(0, "", "Schema changed")
+		p.schema(1, sd)
+		if r.explain {
+			p.item(0, "", "Explanations")
+			for _, c := range FlattenDiff(d) {
+				p.item(1, changeColor(c.ChangeType), r.changeLine(c))
+				if err := r.explainChange(p, 2, c); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return errors.Wrap(p.err, "failed to render the text report")
+}
+
+// explainChange prints the base and revision subschemas and their
+// descriptions for the specified change if explanations are enabled.
+func (r *TextRenderer) explainChange(p *textPrinter, level int, c SchemaChange) error {
+	if !r.explain {
+		return nil
+	}
+	e, err := c.Explain()
+	if err != nil {
+		return err
+	}
+	p.block(level, "Base description:", e.BaseDescription)
+	p.block(level, "Base schema:", e.BaseSchema)
+	p.block(level, "Revision description:", e.RevisionDescription)
+	p.block(level, "Revision schema:", e.RevisionSchema)
+	return nil
+}
+
+// RenderString returns the text rendering of the specified diff.
+func (r *TextRenderer) RenderString(d *diff.Diff) string {
+	buff := &bytes.Buffer{}
+	// writes to a bytes.Buffer do not fail
+	_ = r.Render(buff, d)
+	return buff.String()
+}
+
+func (r *TextRenderer) changeLine(c SchemaChange) string {
+	if r.mode == TextModePaths {
+		return c.Path
+	}
+	switch c.ChangeType {
+	case ChangeTypeFieldAdded:
+		return c.Path + ": field added"
+	case ChangeTypeFieldDeleted:
+		return c.Path + ": field deleted"
+	case ChangeTypeTypeChanged:
+		var from, to string
+		if c.TypeChangeDetails != nil {
+			from, to = typesString(c.TypeChangeDetails.OldType), typesString(c.TypeChangeDetails.NewType)
+		}
+		return fmt.Sprintf("%s: type changed from %s to %s", c.Path, quote(from), quote(to))
+	default:
+		return c.Path + ": " + string(c.ChangeType)
+	}
+}
+
+func changeColor(t ChangeType) string {
+	switch t {
+	case ChangeTypeFieldAdded:
+		return colorGreen
+	case ChangeTypeFieldDeleted:
+		return colorRed
+	default:
+		return colorYellow
+	}
+}
+
+func typesString(t *kinoapi.Types) string {
+	if t == nil {
+		return ""
+	}
+	return strings.Join(t.Slice(), ", ")
+}
+
+// textPrinter writes the indented lines of a text report. The first
+// write error is retained and the subsequent writes are skipped.
+type textPrinter struct {
+	w     io.Writer
+	color bool
+	err   error
+}
+
+func (p *textPrinter) println(level int, color, line string) {
+	if p.err != nil {
+		return
+	}
+	if p.color && color != "" {
+		line = color + line + colorReset
+	}
+	if line != "" {
+		line = strings.Repeat("  ", level) + line
+	}
+	_, p.err = fmt.Fprintln(p.w, line)
+}
+
+// block prints the specified title followed by the indented lines of the
+// specified text. Nothing is printed for an empty text.
+func (p *textPrinter) block(level int, title, text string) {
+	if text == "" {
+		return
+	}
+	p.println(level, "", title)
+	for _, l := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		p.println(level+1, "", l)
+	}
+}
+
+// item prints a list item at the specified nesting level.
+func (p *textPrinter) item(level int, color string, output ...any) {
+	p.println(level, color, "- "+strings.TrimSuffix(fmt.Sprintln(output...), "\n"))
+}
+
+func (p *textPrinter) schema(level int, d *diff.SchemaDiff) { //nolint:gocyclo // flat list of the schema keywords
+	if d.Empty() {
+		return
+	}
+	p.conditional(d.SchemaAdded, level, colorGreen, "Schema added")
+	p.conditional(d.SchemaDeleted, level, colorRed, "Schema deleted")
+	p.conditional(d.CircularRefDiff, level, colorYellow, "Schema circular reference changed")
+
+	if !d.ExtensionsDiff.Empty() {
+		p.item(level, "", "Extensions changed")
+		p.extensions(level+1, d.ExtensionsDiff)
+	}
+	p.subschemas(level, d.OneOfDiff, "OneOf")
+	p.subschemas(level, d.AnyOfDiff, "AnyOf")
+	p.subschemas(level, d.AllOfDiff, "AllOf")
+	if !d.NotDiff.Empty() {
+		p.item(level, "", "Property 'Not' changed")
+		p.schema(level+1, d.NotDiff)
+	}
+
+	p.stringsDiff(level, d.TypeDiff, "Type")
+	p.value(level, d.TitleDiff, "Title")
+	p.value(level, d.FormatDiff, "Format")
+	p.value(level, d.DescriptionDiff, "Description")
+	if !d.EnumDiff.Empty() {
+		p.conditional(len(d.EnumDiff.Added) > 0, level, colorGreen, "New enum values:", d.EnumDiff.Added)
+		p.conditional(len(d.EnumDiff.Deleted) > 0, level, colorRed, "Deleted enum values:", d.EnumDiff.Deleted)
+	}
+	p.value(level, d.DefaultDiff, "Default")
+	p.value(level, d.ExampleDiff, "Example")
+	p.value(level, d.AdditionalPropertiesAllowedDiff, "AdditionalProperties")
+	p.value(level, d.UniqueItemsDiff, "UniqueItems")
+	p.value(level, d.ExclusiveMinDiff, "ExclusiveMin")
+	p.value(level, d.ExclusiveMaxDiff, "ExclusiveMax")
+	p.value(level, d.NullableDiff, "Nullable")
+	p.value(level, d.ReadOnlyDiff, "ReadOnly")
+	p.value(level, d.WriteOnlyDiff, "WriteOnly")
+	p.value(level, d.AllowEmptyValueDiff, "AllowEmptyValue")
+	p.value(level, d.XMLDiff, "XML")
+	p.value(level, d.DeprecatedDiff, "Deprecated")
+	p.value(level, d.MinDiff, "Min")
+	p.value(level, d.MaxDiff, "Max")
+	p.value(level, d.MultipleOfDiff, "MultipleOf")
+	p.value(level, d.MinLengthDiff, "MinLength")
+	p.value(level, d.MaxLengthDiff, "MaxLength")
+	p.value(level, d.PatternDiff, "Pattern")
+	p.value(level, d.MinItemsDiff, "MinItems")
+	p.value(level, d.MaxItemsDiff, "MaxItems")
+
+	if !d.ItemsDiff.Empty() {
+		p.item(level, "", "Items changed")
+		p.schema(level+1, d.ItemsDiff)
+	}
+	if !d.RequiredDiff.Empty() {
+		p.item(level, "", "Required changed")
+		for _, r := range sortedStrings(d.RequiredDiff.Added) {
+			p.item(level+1, colorGreen, "New required property:", r)
+		}
+		for _, r := range sortedStrings(d.RequiredDiff.Deleted) {
+			p.item(level+1, colorRed, "Deleted required property:", r)
+		}
+	}
+	p.value(level, d.MinPropsDiff, "MinProps")
+	p.value(level, d.MaxPropsDiff, "MaxProps")
+	if !d.PropertiesDiff.Empty() {
+		p.item(level, "", "Properties changed")
+		p.properties(level+1, d.PropertiesDiff)
+	}
+	if !d.AdditionalPropertiesDiff.Empty() {
+		p.item(level, "", "AdditionalProperties changed")
+		p.schema(level+1, d.AdditionalPropertiesDiff)
+	}
+	p.conditional(!d.DiscriminatorDiff.Empty(), level, colorYellow, "Discriminator changed")
+}
+
+func (p *textPrinter) properties(level int, d *diff.SchemasDiff) {
+	for _, n := range sortedStrings(d.Added) {
+		p.item(level, colorGreen, "New property:", n)
+	}
+	for _, n := range sortedStrings(d.Deleted) {
+		p.item(level, colorRed, "Deleted property:", n)
+	}
+	names := make([]string, 0, len(d.Modified))
+	for n := range d.Modified {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		p.item(level, "", "Modified property:", n)
+		p.schema(level+1, d.Modified[n])
+	}
+}
+
+func (p *textPrinter) subschemas(level int, d *diff.SubschemasDiff, keyword string) {
+	if d.Empty() {
+		return
+	}
+	p.item(level, "", fmt.Sprintf("Property '%s' changed", keyword))
+	p.conditional(len(d.Added) > 0, level+1, colorGreen, "Schemas added:", d.Added.String())
+	p.conditional(len(d.Deleted) > 0, level+1, colorRed, "Schemas deleted:", d.Deleted.String())
+	for _, m := range d.Modified {
+		p.item(level+1, "", "Modified schema:", m.String())
+		p.schema(level+2, m.Diff)
+	}
+}
+
+func (p *textPrinter) extensions(level int, d *diff.ExtensionsDiff) {
+	for _, e := range sortedStrings(d.Added) {
+		p.item(level, colorGreen, "New extension:", e)
+	}
+	for _, e := range sortedStrings(d.Deleted) {
+		p.item(level, colorRed, "Deleted extension:", e)
+	}
+	names := make([]string, 0, len(d.Modified))
+	for n := range d.Modified {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		p.item(level, "", "Modified extension:", n)
+		for _, op := range d.Modified[n] {
+			p.item(level+1, colorYellow, op.String())
+		}
+	}
+}
+
+func (p *textPrinter) value(level int, d *diff.ValueDiff, title string) {
+	if d.Empty() {
+		return
+	}
+	p.item(level, colorYellow, title, "changed from", quote(d.From), "to", quote(d.To))
+}
+
+func (p *textPrinter) stringsDiff(level int, d *diff.StringsDiff, title string) {
+	if d.Empty() {
+		return
+	}
+	p.item(level, colorYellow, title, "changed from", quote(d.Deleted.String()), "to", quote(d.Added.String()))
+}
+
+func (p *textPrinter) conditional(b bool, level int, color string, output ...any) {
+	if b {
+		p.item(level, color, output...)
+	}
+}
+
+// sortedStrings returns a sorted copy of the specified list so that the
+// rendered diff is not modified.
+func sortedStrings(l utils.StringList) []string {
+	s := append([]string(nil), l...)
+	sort.Strings(s)
+	return s
+}
+
+func quote(value any) any {
+	if value == nil {
+		return "null"
+	}
+	if reflect.ValueOf(value).Kind() == reflect.String {
+		return "'" + value.(string) + "'"
+	}
+	return value
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Upbound Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestTextRenderer(t *testing.T) {
+	d, err := newRevisionDiffWithModifiers("testdata/base.yaml", "testdata/base.yaml", nil,
+		func(r *v1.CustomResourceDefinition) {
+			removeSpecForProviderProperty(r, 0, "tags")
+			addSpecForProviderProperty(r, 0, "newField", v1.JSONSchemaProps{Type: "string"}, nil)
+			p := getSpecForProviderProperty(r, 0, "domainName")
+			p.Type = "integer"
+			addSpecForProviderProperty(r, 0, "domainName", p, nil)
+		})
+	if err != nil {
+		t.Fatalf("newRevisionDiffWithModifiers(...): failed to load CRDs:\n%v", err)
+	}
+	rawDiff, err := d.GetRawDiff()
+	if err != nil {
+		t.Fatalf("GetRawDiff(): error = %v", err)
+	}
+	version := d.baseCRD.Spec.Versions[0].Name
+
+	tests := map[string]struct {
+		reason string
+		opts   []TextRendererOption
+		want   string
+	}{
+		"Verbose": {
+			reason: "The verbose mode should render the nested schema diff",
+			want: `- Schema changed
+  - Properties changed
+    - Modified property: spec
+      - Properties changed
+        - Modified property: forProvider
+          - Properties changed
+            - New property: newField
+            - Deleted property: tags
+            - Modified property: domainName
+              - Type changed from 'string' to 'integer'
+`,
+		},
+		"Terse": {
+			reason: "The terse mode should render a line per flattened change",
+			opts:   []TextRendererOption{WithTextMode(TextModeTerse)},
+			want: `spec.forProvider.domainName: type changed from 'string' to 'integer'
+spec.forProvider.newField: field added
+spec.forProvider.tags: field deleted
+`,
+		},
+		"Paths": {
+			reason: "The paths mode should render only the changed paths",
+			opts:   []TextRendererOption{WithTextMode(TextModePaths)},
+			want: `spec.forProvider.domainName
+spec.forProvider.newField
+spec.forProvider.tags
+`,
+		},
+		"Explain": {
+			reason: "The base and revision subschemas and their descriptions should be rendered for each change if enabled",
+			opts:   []TextRendererOption{WithTextMode(TextModePaths), WithTextExplain(true)},
+			want: `spec.forProvider.domainName
+  Base description:
+    A domain name for which the certificate should be issued
+  Base schema:
+    description: A domain name for which the certificate should be issued
+    type: string
+  Revision description:
+    A domain name for which the certificate should be issued
+  Revision schema:
+    description: A domain name for which the certificate should be issued
+    type: integer
+spec.forProvider.newField
+  Revision schema:
+    type: string
+spec.forProvider.tags
+  Base description:
+    Key-value map of resource tags.
+  Base schema:
+    additionalProperties:
+      type: string
+    description: Key-value map of resource tags.
+    type: object
+`,
+		},
+		"Color": {
+			reason: "Changes should be highlighted with ANSI colors if enabled",
+			opts:   []TextRendererOption{WithTextMode(TextModePaths), WithTextColor(true)},
+			want:   "\033[33mspec.forProvider.domainName\033[0m\n\033[32mspec.forProvider.newField\033[0m\n\033[31mspec.forProvider.tags\033[0m\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := NewTextRenderer(tt.opts...).RenderString(rawDiff[version])
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("\n%s\nRenderString(...): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTextRendererEmptyDiff(t *testing.T) {
+	if got := NewTextRenderer().RenderString(nil); got != "" {
+		t.Errorf("RenderString(nil): got %q, want empty string", got)
+	}
+}